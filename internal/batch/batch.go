@@ -0,0 +1,111 @@
+package batch
+
+import (
+	"sync"
+
+	"github.com/iamhectorsosa/octomap/pkg/processor"
+)
+
+type Status int
+
+const (
+	Pending Status = iota
+	Running
+	Done
+	Failed
+)
+
+// Event reports progress of the repository at Index in the batch.
+type Event struct {
+	Update processor.Update
+	Slug   string
+	Index  int
+	Status Status
+}
+
+// Result is the outcome of mapping a single repository. Description holds
+// the last update the processor reported.
+type Result struct {
+	Err         error
+	Slug        string
+	Description string
+}
+
+// Job pairs a repository slug with its processor configuration. Err is set
+// when the repository could not be configured, failing the job without
+// running it.
+type Job struct {
+	Err    error
+	Config *processor.Config
+	Slug   string
+}
+
+// Run maps every job with at most concurrency processors running at once and
+// returns the results in job order. Events are sent on ch, if provided, and
+// ch is closed once every job has finished.
+func Run(jobs []Job, concurrency int, ch chan<- Event) []Result {
+	if ch != nil {
+		defer close(ch)
+	}
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make([]Result, len(jobs))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, job := range jobs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = run(i, job, ch)
+		}()
+	}
+
+	wg.Wait()
+	return results
+}
+
+func run(index int, job Job, ch chan<- Event) Result {
+	send := func(status Status, update processor.Update) {
+		if ch != nil {
+			ch <- Event{Index: index, Slug: job.Slug, Status: status, Update: update}
+		}
+	}
+
+	result := Result{Slug: job.Slug}
+	if job.Err != nil {
+		result.Err = job.Err
+		send(Failed, processor.Update{Err: result.Err})
+		return result
+	}
+
+	send(Running, processor.Update{})
+
+	updatesCh := make(chan processor.Update)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for update := range updatesCh {
+			// Errors are reported once the repository has failed
+			if update.Err != nil {
+				continue
+			}
+			result.Description = update.Description
+			send(Running, update)
+		}
+	}()
+
+	_, result.Err = processor.New(job.Config, updatesCh).Process()
+	<-done
+
+	if result.Err != nil {
+		send(Failed, processor.Update{Err: result.Err})
+	} else {
+		send(Done, processor.Update{Description: result.Description})
+	}
+	return result
+}
@@ -0,0 +1,112 @@
+package batch
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/iamhectorsosa/octomap/pkg/processor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTarGz(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+
+	for name, content := range files {
+		hdr := &tar.Header{
+			Name: name,
+			Mode: 0600,
+			Size: int64(len(content)),
+		}
+		require.NoError(t, tw.WriteHeader(hdr))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, tw.Close())
+	require.NoError(t, gw.Close())
+	return buf.Bytes()
+}
+
+func TestRun(t *testing.T) {
+	archive := newTarGz(t, map[string]string{
+		"repo-main/main.go": "package main",
+	})
+
+	var running, maxRunning atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			m := maxRunning.Load()
+			if n <= m || maxRunning.CompareAndSwap(m, n) {
+				break
+			}
+		}
+
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(archive)
+	}))
+	defer server.Close()
+
+	tmpDir := t.TempDir()
+	newJob := func(repo string) Job {
+		return Job{
+			Slug: "user/" + repo,
+			Config: &processor.Config{
+				Repo:   repo,
+				Url:    server.URL + "/" + repo,
+				Dir:    "repo-main",
+				Output: tmpDir,
+			},
+		}
+	}
+
+	jobs := []Job{
+		newJob("first"),
+		newJob("missing"),
+		newJob("second"),
+		{Slug: "invalid", Err: errors.New("invalid [user/repo] input")},
+	}
+
+	eventsCh := make(chan Event)
+	var events []Event
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for e := range eventsCh {
+			events = append(events, e)
+		}
+	}()
+
+	results := Run(jobs, 2, eventsCh)
+	<-done
+
+	require.Len(t, results, 4)
+	assert.Equal(t, "user/first", results[0].Slug)
+	assert.NoError(t, results[0].Err)
+	assert.Contains(t, results[0].Description, "generated report")
+	assert.Error(t, results[1].Err)
+	assert.NoError(t, results[2].Err)
+	assert.EqualError(t, results[3].Err, "invalid [user/repo] input")
+	assert.LessOrEqual(t, maxRunning.Load(), int32(2))
+
+	final := map[int]Status{}
+	for _, e := range events {
+		final[e.Index] = e.Status
+	}
+	assert.Equal(t, map[int]Status{0: Done, 1: Failed, 2: Done, 3: Failed}, final)
+}
@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/iamhectorsosa/octomap/internal/batch"
+	"github.com/iamhectorsosa/octomap/internal/model"
+	"github.com/iamhectorsosa/octomap/internal/progress"
+	"github.com/iamhectorsosa/octomap/pkg/processor"
+	"github.com/spf13/cobra"
+)
+
+const (
+	errReadSlugs      = "failed to read repositories from %q\n%v\n"
+	errBatchFailed    = "%d of %d repositories failed\n"
+	errBatchInterrupt = "batch interrupted before all repositories finished\n"
+)
+
+var (
+	concurrency int
+
+	checkMark = lipgloss.NewStyle().Foreground(lipgloss.Color("42")).SetString("✓")
+	errorMark = lipgloss.NewStyle().Foreground(lipgloss.Color("160")).SetString("x")
+)
+
+func init() {
+	addProcessFlags(batchCmd)
+	batchCmd.Flags().IntVarP(&concurrency, "concurrency", "c", 4, "Number of repositories mapped at once")
+	rootCmd.AddCommand(batchCmd)
+}
+
+var batchCmd = &cobra.Command{
+	Use:   "batch [file | user/repo ...]",
+	Short: "Map many repositories in one invocation",
+	Long: "Map many repositories in one invocation, writing one report per repository.\n" +
+		"Arguments are files listing one user/repo per line or user/repo slugs.\n" +
+		"Without arguments, or with \"-\", repositories are read from stdin.",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		slugs, err := readSlugs(args, os.Stdin)
+		if err != nil {
+			return err
+		}
+		if len(slugs) == 0 {
+			cmd.Help()
+			return nil
+		}
+
+		// Repository config files are not read in batch mode, as each
+		// repository would resolve to different flags
+		if err := applyConfig(cmd.Flags(), "", profile); err != nil {
+			return err
+		}
+		if err := validateConcurrency(concurrency); err != nil {
+			return err
+		}
+		if err := validateProgress(progressMode, false); err != nil {
+			return err
+		}
+
+		// Reports are named after the repository only, so repositories of
+		// different owners sharing a name must not overwrite each other
+		if !cmd.Flags().Changed("on-conflict") {
+			onConflict = processor.OnConflictSuffix
+		}
+
+		results, err := runBatch(newBatchJobs(slugs), resolveProgress(progressMode, false, isTerminal()))
+		if err != nil {
+			return err
+		}
+
+		return summarize(os.Stdout, results)
+	},
+}
+
+// readSlugs collects repositories from the arguments, reading files and
+// stdin line by line. Blank lines, comments and duplicates are skipped.
+func readSlugs(args []string, stdin io.Reader) ([]string, error) {
+	if len(args) == 0 {
+		args = []string{"-"}
+	}
+
+	var slugs []string
+	add := func(slug string) {
+		slug = strings.TrimSpace(slug)
+		if slug == "" || strings.HasPrefix(slug, "#") || slices.Contains(slugs, slug) {
+			return
+		}
+		slugs = append(slugs, slug)
+	}
+
+	for _, arg := range args {
+		if arg == "-" {
+			if err := scanLines(stdin, add); err != nil {
+				return nil, fmt.Errorf(errReadSlugs, "stdin", err)
+			}
+			continue
+		}
+
+		info, err := os.Stat(arg)
+		if err != nil || !info.Mode().IsRegular() {
+			add(arg)
+			continue
+		}
+
+		f, err := os.Open(arg)
+		if err != nil {
+			return nil, fmt.Errorf(errReadSlugs, arg, err)
+		}
+		err = scanLines(f, add)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf(errReadSlugs, arg, err)
+		}
+	}
+
+	return slugs, nil
+}
+
+func scanLines(r io.Reader, fn func(line string)) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fn(scanner.Text())
+	}
+	return scanner.Err()
+}
+
+// newBatchJobs configures a job per repository. Repositories with invalid
+// options are kept as failed jobs so they are reported with the others.
+func newBatchJobs(slugs []string) []batch.Job {
+	batchJobs := make([]batch.Job, len(slugs))
+	for i, slug := range slugs {
+		config, err := processor.NewConfig(newOptions(slug))
+		batchJobs[i] = batch.Job{Slug: slug, Config: config, Err: err}
+	}
+	return batchJobs
+}
+
+func runBatch(batchJobs []batch.Job, mode string) ([]batch.Result, error) {
+	if mode == progress.None {
+		return batch.Run(batchJobs, concurrency, nil), nil
+	}
+
+	eventsCh := make(chan batch.Event)
+	resultsCh := make(chan []batch.Result, 1)
+	go func() {
+		resultsCh <- batch.Run(batchJobs, concurrency, eventsCh)
+	}()
+
+	switch mode {
+	case progress.TUI:
+		slugs := make([]string, len(batchJobs))
+		for i, job := range batchJobs {
+			slugs[i] = job.Slug
+		}
+		final, err := tea.NewProgram(model.NewBatch(slugs, eventsCh)).Run()
+		if err != nil {
+			return nil, err
+		}
+		if m, ok := final.(interface{ Complete() bool }); ok && !m.Complete() {
+			return nil, fmt.Errorf(errBatchInterrupt)
+		}
+	case progress.JSON:
+		progress.WriteBatchJSON(os.Stderr, eventsCh)
+	default:
+		progress.WriteBatchPlain(os.Stderr, eventsCh)
+	}
+
+	return <-resultsCh, nil
+}
+
+// summarize prints one line per repository and fails if any of them failed.
+func summarize(w io.Writer, results []batch.Result) error {
+	failed := 0
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+			fmt.Fprintf(w, "%s %s: %v\n", errorMark, result.Slug, strings.TrimSpace(result.Err.Error()))
+			continue
+		}
+		fmt.Fprintf(w, "%s %s: %s\n", checkMark, result.Slug, result.Description)
+	}
+
+	fmt.Fprintf(w, "\n%d succeeded, %d failed\n", len(results)-failed, failed)
+	if failed > 0 {
+		return fmt.Errorf(errBatchFailed, failed, len(results))
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/iamhectorsosa/octomap/internal/batch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadSlugs(t *testing.T) {
+	tmpDir := t.TempDir()
+	reposFile := filepath.Join(tmpDir, "repos.txt")
+	err := os.WriteFile(reposFile, []byte("# backend\nuser/api\n\nuser/auth\nuser/api\n"), 0644)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		stdin string
+		args  []string
+		want  []string
+	}{
+		{
+			name: "Positional slugs",
+			args: []string{"user/a", "user/b"},
+			want: []string{"user/a", "user/b"},
+		},
+		{
+			name: "Repositories file",
+			args: []string{reposFile, "user/web"},
+			want: []string{"user/api", "user/auth", "user/web"},
+		},
+		{
+			name:  "Stdin without arguments",
+			stdin: "user/a\n  user/b  \n",
+			want:  []string{"user/a", "user/b"},
+		},
+		{
+			name:  "Stdin with dash",
+			args:  []string{"user/a", "-"},
+			stdin: "user/a\nuser/c\n",
+			want:  []string{"user/a", "user/c"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readSlugs(tt.args, strings.NewReader(tt.stdin))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	var buf bytes.Buffer
+	err := summarize(&buf, []batch.Result{
+		{Slug: "user/a", Description: "generated report: a.json"},
+		{Slug: "user/b", Err: errors.New("unexpected status code: 404")},
+	})
+
+	assert.EqualError(t, err, fmt.Sprintf(errBatchFailed, 1, 2))
+	assert.Contains(t, buf.String(), "user/a: generated report: a.json")
+	assert.Contains(t, buf.String(), "user/b: unexpected status code: 404")
+	assert.Contains(t, buf.String(), "1 succeeded, 1 failed")
+
+	buf.Reset()
+	err = summarize(&buf, []batch.Result{{Slug: "user/a"}})
+	assert.NoError(t, err)
+}
+
+func TestNewBatchJobs(t *testing.T) {
+	defer func(previous bool) { stdout = previous }(stdout)
+	stdout = true
+
+	jobs := newBatchJobs([]string{"user/repo", "invalid"})
+	require.Len(t, jobs, 2)
+	assert.NoError(t, jobs[0].Err)
+	assert.NotNil(t, jobs[0].Config)
+	assert.Equal(t, "invalid", jobs[1].Slug)
+	assert.Error(t, jobs[1].Err)
+}
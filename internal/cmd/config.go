@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"strconv"
+
+	"github.com/iamhectorsosa/octomap/internal/config"
+	"github.com/spf13/pflag"
+)
+
+// applyConfig fills every flag that was not set on the command line from the
+// config files. Precedence, from highest to lowest: flags, the current
+// directory, the repository being mapped and $XDG_CONFIG_HOME/octomap. The
+// repository config is only fetched with repo-config and a slug.
+func applyConfig(flags *pflag.FlagSet, slug, profile string) error {
+	user, err := config.Find(config.UserDir())
+	if err != nil {
+		return err
+	}
+	local, err := config.Find(".")
+	if err != nil {
+		return err
+	}
+
+	// The branch must be known before the repository config can be fetched
+	userValues, _ := user.Resolve(profile)
+	localValues, _ := local.Resolve(profile)
+	values := config.Merge(userValues, localValues)
+	repoBranch := branch
+	if v := values.Branch; v != nil && !flags.Changed("branch") {
+		repoBranch = *v
+	}
+	fetch := repoConfig
+	if v := values.Remote; v != nil && !flags.Changed("repo-config") {
+		fetch = *v
+	}
+
+	var repo *config.File
+	if slug != "" && fetch {
+		repo, err = config.Fetch(slug, repoBranch)
+		if err != nil {
+			return err
+		}
+	}
+
+	values, err = config.Load(profile, user, repo.Restricted(), local)
+	if err != nil {
+		return err
+	}
+
+	return setFlags(flags, map[string][]string{
+		"branch":          formatPtr(values.Branch, identity),
+		"dir":             values.Dir,
+		"output":          formatPtr(values.Output, identity),
+		"on-conflict":     formatPtr(values.Conflict, identity),
+		"mkdir":           formatPtr(values.MkDir, strconv.FormatBool),
+		"progress":        formatPtr(values.Progress, identity),
+		"jobs":            formatPtr(values.Jobs, strconv.Itoa),
+		"max-size":        formatPtr(values.MaxSize, strconv.Itoa),
+		"skipped":         formatPtr(values.Skipped, strconv.FormatBool),
+		"contains":        formatPtr(values.Contains, identity),
+		"stdout":          formatPtr(values.Stdout, strconv.FormatBool),
+		"include":         values.Include,
+		"exclude":         values.Exclude,
+		"path":            values.Paths,
+		"lang":            values.Lang,
+		"mode":            formatPtr(values.Mode, identity),
+		"full":            values.Full,
+		"tree-only":       formatPtr(values.TreeOnly, strconv.FormatBool),
+		"tree-format":     formatPtr(values.TreeFormat, identity),
+		"format":          formatPtr(values.Format, identity),
+		"compress":        formatPtr(values.Compress, identity),
+		"lfs":             formatPtr(values.LFS, identity),
+		"submodules":      formatPtr(values.Submodules, identity),
+		"submodule-depth": formatPtr(values.Depth, strconv.Itoa),
+		"follow-symlinks": formatPtr(values.Symlinks, strconv.FormatBool),
+		"gitignore":       formatPtr(values.Ignore, strconv.FormatBool),
+		"deterministic":   formatPtr(values.Deterministic, strconv.FormatBool),
+		"minify":          formatPtr(values.Minify, strconv.FormatBool),
+		"redact":          formatPtr(values.Redact, identity),
+		"redact-pattern":  values.RedactPatterns,
+	})
+}
+
+// setFlags sets every unchanged flag with a non-nil value. Slice flags are
+// replaced as a whole, other flags are set from the first value.
+func setFlags(flags *pflag.FlagSet, values map[string][]string) error {
+	for name, value := range values {
+		flag := flags.Lookup(name)
+		if value == nil || flag == nil || flag.Changed {
+			continue
+		}
+		if sliceValue, ok := flag.Value.(pflag.SliceValue); ok {
+			if err := sliceValue.Replace(value); err != nil {
+				return err
+			}
+			continue
+		}
+		if len(value) == 0 {
+			continue
+		}
+		if err := flags.Set(name, value[0]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func formatPtr[T any](v *T, format func(T) string) []string {
+	if v == nil {
+		return nil
+	}
+	return []string{format(*v)}
+}
+
+func identity(s string) string { return s }
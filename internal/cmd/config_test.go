@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/iamhectorsosa/octomap/internal/config"
+	"github.com/iamhectorsosa/octomap/pkg/processor"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetFlags(t *testing.T) {
+	var (
+		dir     string
+		jobs    int
+		include []string
+	)
+
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.StringVar(&dir, "dir", "", "")
+	flags.IntVar(&jobs, "jobs", 0, "")
+	flags.StringSliceVar(&include, "include", []string{}, "")
+	require.NoError(t, flags.Parse([]string{"--dir", "cmd"}))
+
+	four := 4
+
+	err := setFlags(flags, map[string][]string{
+		"dir":     {"api"},
+		"jobs":    formatPtr(&four, strconv.Itoa),
+		"include": {".go", ".mod"},
+		"missing": {"ignored"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "cmd", dir, "flags set on the command line take precedence")
+	assert.Equal(t, 4, jobs)
+	assert.Equal(t, []string{".go", ".mod"}, include)
+}
+
+func TestApplyConfigRepoConfig(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path == "/user/repo/main/octomap.yaml" {
+			w.Write([]byte("jobs: 3\n"))
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	origRawURL := config.RawURL
+	config.RawURL = server.URL + "/%s/%s/%s"
+	defer func() { config.RawURL = origRawURL }()
+
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(t.TempDir()))
+	defer os.Chdir(wd)
+
+	for _, tt := range []struct {
+		args     []string
+		requests int
+		jobs     int
+	}{
+		{args: nil, requests: 0, jobs: 0},
+		{args: []string{"--repo-config"}, requests: 1, jobs: 3},
+	} {
+		requests, jobs, repoConfig, branch = 0, 0, false, "main"
+		flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		flags.BoolVar(&repoConfig, "repo-config", false, "")
+		flags.IntVar(&jobs, "jobs", 0, "")
+		require.NoError(t, flags.Parse(tt.args))
+
+		require.NoError(t, applyConfig(flags, "user/repo", ""))
+		assert.Equal(t, tt.requests, requests)
+		assert.Equal(t, tt.jobs, jobs)
+	}
+}
+
+func TestSavedSelection(t *testing.T) {
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	for _, name := range []string{"repo-main/main.go", "repo-main/cmd/main.go", "repo-main/xmain.go"} {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: 13}))
+		_, err := tw.Write([]byte("package main\n"))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gw.Close())
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(buf.Bytes())
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	_, err := config.SavePaths(dir, "", []string{"main.go"})
+	require.NoError(t, err)
+
+	f, err := config.Find(dir)
+	require.NoError(t, err)
+	values, err := config.Load("", f)
+	require.NoError(t, err)
+
+	cfg, err := processor.NewConfig(processor.Options{
+		Slug:   "user/repo",
+		Branch: "main",
+		Stdout: true,
+		Paths:  values.Paths,
+	})
+	require.NoError(t, err)
+	cfg.Url = server.URL
+
+	data, err := processor.New(cfg, nil).Process()
+	require.NoError(t, err)
+	assert.Equal(t, processor.RepositoryData{"main.go": "package main\n"}, data)
+}
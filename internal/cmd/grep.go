@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/iamhectorsosa/octomap/pkg/processor"
+	"github.com/spf13/cobra"
+)
+
+var (
+	ignoreCase       bool
+	grepContext      int
+	filesWithMatches bool
+)
+
+func init() {
+	addFilterFlags(grepCmd, false)
+	grepCmd.Flags().BoolVarP(&ignoreCase, "ignore-case", "i", false, "Match case-insensitively")
+	grepCmd.Flags().IntVarP(&grepContext, "context", "C", 0, "Number of lines shown around each match")
+	grepCmd.Flags().BoolVarP(&filesWithMatches, "files-with-matches", "l", false, "Only print the paths of files with matches")
+	rootCmd.AddCommand(grepCmd)
+}
+
+var grepCmd = &cobra.Command{
+	Use:   "grep <pattern> <user/repo>",
+	Short: "Search the contents of a repository",
+	Long: "Search the contents of a repository without writing a report. The archive is\n" +
+		"streamed through the same filters as the main command and matching lines are\n" +
+		"printed with their path and line number.",
+	Args: func(cmd *cobra.Command, args []string) error {
+		return validateGrepArgs(args)
+	},
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pattern, slug := args[0], args[1]
+		if err := applyConfig(cmd.Flags(), slug, profile); err != nil {
+			return err
+		}
+
+		re, err := createGrepPattern(pattern, ignoreCase)
+		if err != nil {
+			return err
+		}
+		if err := validateContext(grepContext); err != nil {
+			return err
+		}
+
+		opts := newOptions(slug)
+		opts.Stdout = true
+		config, err := processor.NewConfig(opts)
+		if err != nil {
+			return err
+		}
+
+		return processor.New(config, nil).Grep(re, grepContext, func(r processor.GrepResult) error {
+			return writeGrepResult(os.Stdout, r, filesWithMatches)
+		})
+	},
+}
+
+// writeGrepResult prints a file's matches like grep: path:line:text for
+// matching lines, path-line-text for context and -- between groups.
+func writeGrepResult(w io.Writer, r processor.GrepResult, filesOnly bool) error {
+	if filesOnly {
+		_, err := fmt.Fprintln(w, r.Path)
+		return err
+	}
+
+	for i, line := range r.Lines {
+		if i > 0 && line.Number != r.Lines[i-1].Number+1 {
+			if _, err := fmt.Fprintln(w, "--"); err != nil {
+				return err
+			}
+		}
+		sep := "-"
+		if line.Match {
+			sep = ":"
+		}
+		if _, err := fmt.Fprintf(w, "%s%s%d%s%s\n", r.Path, sep, line.Number, sep, line.Text); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/iamhectorsosa/octomap/pkg/processor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteGrepResult(t *testing.T) {
+	result := processor.GrepResult{
+		Path: "main.go",
+		Lines: []processor.GrepLine{
+			{Text: "package main", Number: 1},
+			{Text: "// TODO: one", Number: 2, Match: true},
+			{Text: "// TODO: two", Number: 9, Match: true},
+			{Text: "}", Number: 10},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		want      string
+		filesOnly bool
+	}{
+		{
+			name: "lines",
+			want: "main.go-1-package main\nmain.go:2:// TODO: one\n--\nmain.go:9:// TODO: two\nmain.go-10-}\n",
+		},
+		{
+			name:      "files with matches",
+			filesOnly: true,
+			want:      "main.go\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, writeGrepResult(&buf, result, tt.filesOnly))
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
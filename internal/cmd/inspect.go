@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/iamhectorsosa/octomap/pkg/processor"
+	"github.com/spf13/cobra"
+)
+
+var (
+	inspectJSON bool
+	inspectTop  int
+)
+
+func init() {
+	addProcessFlags(inspectCmd)
+	inspectCmd.Flags().BoolVar(&inspectJSON, "json", false, "Print the inspection as JSON")
+	inspectCmd.Flags().IntVar(&inspectTop, "top", 10, "Number of largest files listed")
+	rootCmd.AddCommand(inspectCmd)
+}
+
+var inspectCmd = &cobra.Command{
+	Use:   "inspect [user/repo]",
+	Short: "Preview what would be mapped without reading file contents",
+	Long: "Preview what would be mapped from the archive headers only: file counts,\n" +
+		"total size, estimated tokens, the largest files and which files each\n" +
+		"include, exclude and lang rule matched or dropped.",
+	Args: func(cmd *cobra.Command, args []string) error {
+		return validateRootArgs(args)
+	},
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 1 {
+			cmd.Help()
+			return nil
+		}
+
+		slug := args[0]
+		if err := applyConfig(cmd.Flags(), slug, profile); err != nil {
+			return err
+		}
+
+		config, err := processor.NewConfig(newOptions(slug))
+		if err != nil {
+			return err
+		}
+
+		inspection, err := processor.New(config, nil).Inspect(inspectTop)
+		if err != nil {
+			return err
+		}
+
+		if inspectJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(inspection)
+		}
+		writeInspection(os.Stdout, inspection)
+		return nil
+	},
+}
+
+// writeInspection renders an inspection as plain text.
+func writeInspection(w io.Writer, i *processor.Inspection) {
+	fmt.Fprintf(w, "%s (%s)\n", i.Repo, i.Url)
+	fmt.Fprintf(w, "archive:  %d directories, %d files, %d bytes\n", i.Dirs, i.Files, i.Bytes)
+	fmt.Fprintf(w, "selected: %d files, %d bytes, ~%d tokens\n", i.Selected, i.SelectedBytes, i.Tokens)
+
+	if len(i.Largest) > 0 {
+		fmt.Fprintln(w, "\nlargest files:")
+		for _, f := range i.Largest {
+			fmt.Fprintf(w, "  %10d bytes %9d tokens  %s\n", f.Size, f.Tokens, f.Path)
+		}
+	}
+
+	for _, r := range i.Rules {
+		fmt.Fprintf(w, "\n%s %s: %d matched, %d dropped\n", r.Kind, r.Rule, len(r.Matched), len(r.Dropped))
+		for _, path := range r.Matched {
+			fmt.Fprintf(w, "  + %s\n", path)
+		}
+		for _, path := range r.Dropped {
+			fmt.Fprintf(w, "  - %s\n", path)
+		}
+	}
+
+	if len(i.Unmatched) > 0 {
+		fmt.Fprintf(w, "\nunmatched: %d dropped\n", len(i.Unmatched))
+		for _, path := range i.Unmatched {
+			fmt.Fprintf(w, "  - %s\n", path)
+		}
+	}
+
+	reasons := make([]string, 0, len(i.Skipped))
+	for reason := range i.Skipped {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+	for _, reason := range reasons {
+		fmt.Fprintf(w, "\n%s: %d skipped\n", reason, len(i.Skipped[reason]))
+		for _, path := range i.Skipped[reason] {
+			fmt.Fprintf(w, "  - %s\n", path)
+		}
+	}
+}
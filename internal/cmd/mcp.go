@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/iamhectorsosa/octomap/internal/mcp"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(mcpCmd)
+}
+
+var mcpCmd = &cobra.Command{
+	Use:   "mcp",
+	Short: "Serve repository maps to agents over the Model Context Protocol",
+	Long: "Serve repository maps over the Model Context Protocol on stdin and stdout.\n" +
+		"Agents map a repository once with map_repository and explore it with\n" +
+		"list_tree, read_file and search_repository.",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return mcp.NewServer().Serve(os.Stdin, os.Stdout)
+	},
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+	cfg "github.com/iamhectorsosa/octomap/internal/config"
+	"github.com/iamhectorsosa/octomap/internal/model"
+	"github.com/iamhectorsosa/octomap/pkg/processor"
+)
+
+// pickFiles lets the user select the files to map from a headers-only pass
+// over the archive. It returns nil when the picker is cancelled.
+func pickFiles(config *processor.Config) ([]string, error) {
+	fmt.Fprintf(os.Stderr, "inspecting: %s\n", config.Url)
+
+	// Every file is listed, so a saved selection can be widened again
+	all := *config
+	all.Paths = nil
+	inspection, err := processor.New(&all, nil).Inspect(-1)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := tea.NewProgram(model.NewPicker(inspection.Largest)).Run()
+	if err != nil {
+		return nil, err
+	}
+	picker, ok := result.(interface {
+		Confirmed() bool
+		Selected() []string
+	})
+	if !ok || !picker.Confirmed() {
+		return nil, nil
+	}
+
+	picked := picker.Selected()
+	if saveSelection {
+		path, err := cfg.SavePaths(".", profile, picked)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(os.Stderr, "saved selection: %s\n", path)
+	}
+	return picked, nil
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/iamhectorsosa/octomap/pkg/query"
+	"github.com/spf13/cobra"
+)
+
+var queryJSON bool
+
+func init() {
+	queryCmd.Flags().BoolVar(&queryJSON, "json", false, "Print one JSON object per matching file")
+	rootCmd.AddCommand(queryCmd)
+}
+
+var queryCmd = &cobra.Command{
+	Use:   "query <report.json> <expression>",
+	Short: "Filter and project the files of a report",
+	Long: "Filter and project the files of a nested, flat or NDJSON report, or - for\n" +
+		"stdin, optionally compressed with gzip or zstd. Expressions combine predicates on path, dir, name, ext, language,\n" +
+		"content, size, tokens and lines:\n\n" +
+		"  octomap query report.json 'path ~ \"cmd/**/*.go\" and tokens > 1k'\n" +
+		"  octomap query report.json 'select path, size where language = go'",
+	Args: func(cmd *cobra.Command, args []string) error {
+		return validateQueryArgs(args)
+	},
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		q, err := query.Parse(args[1])
+		if err != nil {
+			return err
+		}
+
+		var r io.Reader = os.Stdin
+		if args[0] != "-" {
+			file, err := os.Open(args[0])
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			r = file
+		}
+
+		w := bufio.NewWriter(os.Stdout)
+		defer w.Flush()
+		return query.Read(bufio.NewReader(r), func(f *query.File) error {
+			if !q.Match(f) {
+				return nil
+			}
+			return writeQueryResult(w, f, q.Fields, queryJSON)
+		})
+	},
+}
+
+// writeQueryResult prints the selected fields of a file, tab-separated or as
+// a JSON object.
+func writeQueryResult(w io.Writer, f *query.File, fields []string, asJSON bool) error {
+	if asJSON {
+		obj := make(map[string]any, len(fields))
+		for _, field := range fields {
+			obj[field] = f.Value(field)
+		}
+		return json.NewEncoder(w).Encode(obj)
+	}
+
+	values := make([]string, len(fields))
+	for i, field := range fields {
+		values[i] = fmt.Sprint(f.Value(field))
+	}
+	_, err := fmt.Fprintln(w, strings.Join(values, "\t"))
+	return err
+}
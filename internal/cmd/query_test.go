@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/iamhectorsosa/octomap/pkg/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteQueryResult(t *testing.T) {
+	file := &query.File{Path: "cmd/main.go", Language: "Go", Size: 1200, Tokens: 300}
+
+	tests := []struct {
+		name   string
+		want   string
+		fields []string
+		asJSON bool
+	}{
+		{
+			name:   "fields",
+			want:   "cmd/main.go\t1200\tGo\n",
+			fields: []string{"path", "size", "language"},
+		},
+		{
+			name:   "json",
+			want:   "{\"path\":\"cmd/main.go\",\"tokens\":300}\n",
+			fields: []string{"path", "tokens"},
+			asJSON: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, writeQueryResult(&buf, file, tt.fields, tt.asJSON))
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
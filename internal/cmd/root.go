@@ -1,31 +1,148 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/iamhectorsosa/octomap/internal/model"
+	"github.com/iamhectorsosa/octomap/internal/progress"
+	"github.com/iamhectorsosa/octomap/pkg/compress"
 	"github.com/iamhectorsosa/octomap/pkg/processor"
+	"github.com/mattn/go-isatty"
 	"github.com/spf13/cobra"
 )
 
 var (
-	branch  string
-	dir     string
-	include []string
-	exclude []string
-	output  string
-	stdout  bool
+	branch         string
+	dirs           []string
+	include        []string
+	langs          []string
+	jobs           int
+	maxSize        int
+	listSkipped    bool
+	contains       string
+	exclude        []string
+	paths          []string
+	output         string
+	profile        string
+	progressMode   string
+	pick           bool
+	saveSelection  bool
+	minify         bool
+	mode           string
+	full           []string
+	redact         string
+	redactPatterns []string
+	stdout         bool
+	treeOnly       bool
+	treeFormat     string
+	format         string
+	deterministic  bool
+	onConflict     string
+	mkdir          bool
+	compression    string
+	lfs            string
+	submodules     string
+	submoduleDepth int
+	followSymlinks bool
+	gitignore      bool
+	repoConfig     bool
 )
 
 func init() {
-	rootCmd.Flags().StringVarP(&branch, "branch", "b", "main", "Branch to clone")
-	rootCmd.Flags().StringVarP(&dir, "dir", "d", "", "Target directory within the repository")
-	rootCmd.Flags().StringSliceVarP(&include, "include", "i", []string{}, "Comma-separated list of included file extensions")
-	rootCmd.Flags().StringSliceVarP(&exclude, "exclude", "e", []string{}, "Comma-separated list of excluded file extensions")
+	addProcessFlags(rootCmd)
 	rootCmd.Flags().BoolVarP(&stdout, "stdout", "s", false, "Output to stdout. Note: output will be ignored.")
-	rootCmd.Flags().StringVarP(&output, "output", "o", "", "Output directory for the generated JSON file")
+	rootCmd.Flags().StringVar(&contains, "contains", "", "Only map files whose content matches this regular expression")
+	rootCmd.Flags().BoolVar(&pick, "pick", false, "Interactively pick the files to map before mapping")
+	rootCmd.Flags().BoolVar(&saveSelection, "save-selection", false, "Save the picked files as the paths list of --profile in the local config file")
+}
+
+// addProcessFlags registers the flags shared by every command that maps
+// repositories.
+func addProcessFlags(cmd *cobra.Command) {
+	addFilterFlags(cmd, true)
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Output directory, file path or template such as {owner}/{repo}@{ref}.{ext}")
+	cmd.Flags().StringVar(&onConflict, "on-conflict", processor.OnConflictOverwrite, "When the output file exists: overwrite, suffix, fail or append for sqlite")
+	cmd.Flags().BoolVar(&mkdir, "mkdir", false, "Create missing output directories")
+	cmd.Flags().IntVarP(&jobs, "jobs", "j", 0, "Number of concurrent file processing workers (default: number of CPUs)")
+	cmd.Flags().BoolVar(&listSkipped, "skipped", false, "List every skipped file and its reason in the manifest")
+	cmd.Flags().StringVarP(&progressMode, "progress", "p", progress.Auto, "Progress reporting: auto, tui, plain, json or none")
+	cmd.Flags().StringVarP(&mode, "mode", "m", processor.ModeFull, "Content mode: full or outline")
+	cmd.Flags().StringSliceVar(&full, "full", []string{}, "Comma-separated glob patterns of files kept in full by the outline mode")
+	cmd.Flags().BoolVar(&minify, "minify", false, "Strip comments, license headers and redundant blank lines")
+	cmd.Flags().StringVar(&lfs, "lfs", processor.LFSPointer, "Git LFS pointers: pointer, skip or resolve")
+	cmd.Flags().StringVar(&submodules, "submodules", processor.SubmodulesSkip, "Git submodules: skip, pointer or expand")
+	cmd.Flags().IntVar(&submoduleDepth, "submodule-depth", 1, "How many levels of nested submodules are expanded, 0 expands none")
+	cmd.Flags().BoolVar(&followSymlinks, "follow-symlinks", false, "Map the target of links within the repository at their path")
+	cmd.Flags().BoolVar(&gitignore, "gitignore", false, "Skip files matching the .gitignore file at the root of the repository")
+	cmd.Flags().BoolVar(&treeOnly, "tree-only", false, "Map the directory tree with file sizes instead of contents")
+	cmd.Flags().StringVar(&treeFormat, "tree-format", processor.TreeFormatJSON, "Tree-only output format: json or ascii")
+	cmd.Flags().StringVar(&format, "format", processor.FormatJSON, "Report format: json or sqlite")
+	cmd.Flags().StringVar(&compression, "compress", compress.None, "Compress the report: none, gzip or zstd")
+	cmd.Flags().BoolVar(&deterministic, "deterministic", false, "Write reproducible reports named by branch and commit, without timestamps")
+	cmd.Flags().StringVar(&redact, "redact", processor.RedactNone, "Secret redaction: none, mask, drop-file or fail")
+	cmd.Flags().StringArrayVar(&redactPatterns, "redact-pattern", []string{}, "Additional regular expression treated as a secret, can be repeated")
+}
+
+// addFilterFlags registers the flags selecting which files of a repository
+// are read. Commands with conflicting shorthands register them without.
+func addFilterFlags(cmd *cobra.Command, shorthands bool) {
+	short := func(s string) string {
+		if shorthands {
+			return s
+		}
+		return ""
+	}
+
+	cmd.Flags().StringVarP(&branch, "branch", "b", "main", "Branch to clone")
+	cmd.Flags().StringSliceVarP(&dirs, "dir", "d", []string{}, "Target directory within the repository, can be repeated")
+	cmd.Flags().StringSliceVarP(&include, "include", short("i"), []string{}, "Comma-separated list of included file extensions")
+	cmd.Flags().StringSliceVarP(&exclude, "exclude", short("e"), []string{}, "Comma-separated list of excluded file extensions")
+	cmd.Flags().StringSliceVar(&paths, "path", []string{}, "Comma-separated list of exact file paths to map, relative to the target directory")
+	cmd.Flags().StringSliceVarP(&langs, "lang", short("l"), []string{}, "Comma-separated list of included languages, e.g. go,ts")
+	cmd.Flags().IntVar(&maxSize, "max-size", 0, "Skip files larger than this many bytes (default: no limit)")
+	cmd.Flags().StringVar(&profile, "profile", "", "Named profile from the octomap config files")
+	cmd.Flags().BoolVar(&repoConfig, "repo-config", false, "Read the octomap config file at the root of the repository")
+}
+
+// newOptions collects the processor options from the shared flags.
+func newOptions(slug string) processor.Options {
+	return processor.Options{
+		Slug:           slug,
+		Branch:         branch,
+		Dirs:           dirs,
+		Output:         output,
+		OnConflict:     onConflict,
+		MkDir:          mkdir,
+		Compress:       compression,
+		LFS:            lfs,
+		Submodules:     submodules,
+		SubmoduleDepth: submoduleDepth,
+		FollowSymlinks: followSymlinks,
+		Gitignore:      gitignore,
+		Stdout:         stdout,
+		Include:        include,
+		Exclude:        exclude,
+		Paths:          paths,
+		Langs:          langs,
+		Jobs:           jobs,
+		MaxSize:        maxSize,
+		ListSkipped:    listSkipped,
+		Mode:           mode,
+		Full:           full,
+		Minify:         minify,
+		Redact:         redact,
+		TreeOnly:       treeOnly,
+		TreeFormat:     treeFormat,
+		Format:         format,
+		Deterministic:  deterministic,
+		RedactPatterns: redactPatterns,
+		Contains:       contains,
+		Token:          os.Getenv("GITHUB_TOKEN"),
+	}
 }
 
 var rootCmd = &cobra.Command{
@@ -42,30 +159,104 @@ var rootCmd = &cobra.Command{
 		}
 
 		slug := args[0]
-		config, err := processor.NewConfig(slug, branch, dir, output, stdout, include, exclude)
+		if err := applyConfig(cmd.Flags(), slug, profile); err != nil {
+			return err
+		}
+
+		if err := validateProgress(progressMode, stdout); err != nil {
+			return err
+		}
+
+		if err := validatePick(pick, saveSelection, profile, stdout, isTerminal()); err != nil {
+			return err
+		}
+
+		if err := validateCompressStdout(compression, stdout, isTerminal()); err != nil {
+			return err
+		}
+
+		config, err := processor.NewConfig(newOptions(slug))
 		if err != nil {
 			return err
 		}
 
-		// If stdout wasn't provided run the Bubbletea program
-		if !stdout {
+		if pick {
+			picked, err := pickFiles(config)
+			if err != nil || picked == nil {
+				return err
+			}
+			config.Paths = picked
+		}
+
+		mode := resolveProgress(progressMode, stdout, isTerminal())
+
+		// Run the Bubbletea program when progress is rendered as a TUI
+		if mode == progress.TUI {
 			if _, err := tea.NewProgram(model.New(config)).Run(); err != nil {
 				return err
 			}
 			return nil
 		}
 
-		// If stdout was provided, create a new processor and run a process
-		p := processor.New(config, nil)
-		data, err := p.Process(0)
+		// Otherwise create a new processor and report progress on stderr
+		data, err := process(config, mode)
 		if err != nil {
 			return err
 		}
-		fmt.Fprint(os.Stdout, data)
-		return nil
+		if !stdout {
+			return nil
+		}
+
+		return writeReport(os.Stdout, data, config)
 	},
 }
 
+// writeReport writes a report to w as JSON, or as an ASCII tree for tree-only
+// reports in that format, through the compression of the config.
+func writeReport(out io.Writer, data processor.RepositoryData, config *processor.Config) error {
+	w, err := compress.NewWriter(out, config.Compress)
+	if err != nil {
+		return err
+	}
+	if config.TreeOnly && config.TreeFormat == processor.TreeFormatASCII {
+		if _, err := io.WriteString(w, processor.RenderTree(data)); err != nil {
+			return err
+		}
+	} else {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(data); err != nil {
+			return fmt.Errorf("encoding report error: %v", err)
+		}
+	}
+	return w.Close()
+}
+
+func process(config *processor.Config, mode string) (processor.RepositoryData, error) {
+	if mode == progress.None {
+		return processor.New(config, nil).Process()
+	}
+
+	updatesCh := make(chan processor.Update)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if mode == progress.JSON {
+			progress.WriteJSON(os.Stderr, updatesCh)
+			return
+		}
+		progress.WritePlain(os.Stderr, updatesCh)
+	}()
+
+	data, err := processor.New(config, updatesCh).Process()
+	<-done
+	return data, err
+}
+
+func isTerminal() bool {
+	return isatty.IsTerminal(os.Stdout.Fd()) || isatty.IsCygwinTerminal(os.Stdout.Fd())
+}
+
 func Execute() error {
 	return rootCmd.Execute()
 }
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/iamhectorsosa/octomap/pkg/compress"
+	"github.com/iamhectorsosa/octomap/pkg/processor"
+	"github.com/iamhectorsosa/octomap/pkg/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteReport(t *testing.T) {
+	data := processor.RepositoryData{
+		"main.go": "package main\n",
+		"cmd":     map[string]interface{}{"root.go": "package cmd\n"},
+	}
+
+	for _, method := range compress.Methods {
+		t.Run(method, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, writeReport(&buf, data, &processor.Config{Compress: method}))
+
+			files := map[string]string{}
+			require.NoError(t, query.Read(&buf, func(f *query.File) error {
+				files[f.Path] = f.Content
+				return nil
+			}))
+			assert.Equal(t, map[string]string{
+				"main.go":     "package main\n",
+				"cmd/root.go": "package cmd\n",
+			}, files)
+		})
+	}
+}
+
+func TestWriteReportTreeOnly(t *testing.T) {
+	var archive bytes.Buffer
+	gw := gzip.NewWriter(&archive)
+	tw := tar.NewWriter(gw)
+	for name, content := range map[string]string{
+		"repo-main/main.go":     "package main\n",
+		"repo-main/cmd/root.go": "package cmd\n\nvar x = 1\n",
+	} {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content))}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gw.Close())
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(archive.Bytes())
+	}))
+	defer server.Close()
+
+	config, err := processor.NewConfig(processor.Options{
+		Slug:     "user/repo",
+		Branch:   "main",
+		Stdout:   true,
+		TreeOnly: true,
+	})
+	require.NoError(t, err)
+	config.Url = server.URL
+
+	data, err := processor.New(config, nil).Process()
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, writeReport(&buf, data, config))
+
+	var tree map[string]any
+	decoder := json.NewDecoder(&buf)
+	decoder.UseNumber()
+	require.NoError(t, decoder.Decode(&tree))
+	assert.Equal(t, map[string]any{
+		"main.go": json.Number("13"),
+		"cmd":     map[string]any{"root.go": json.Number("23")},
+	}, tree)
+}
@@ -2,11 +2,29 @@ package cmd
 
 import (
 	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/iamhectorsosa/octomap/internal/progress"
+	"github.com/iamhectorsosa/octomap/pkg/compress"
 )
 
 const (
-	maxNumArgs     = 1
-	invalidNumArgs = "accepts at most %d arg(s), received %d\n"
+	maxNumArgs          = 1
+	invalidNumArgs      = "accepts at most %d arg(s), received %d\n"
+	invalidProgressMode = "invalid progress, must be one of %s, received %q\n"
+	invalidProgressTUI  = "invalid progress, %q cannot be used with stdout\n"
+	invalidConcurrency  = "invalid concurrency, must be at least 1, received %d\n"
+	invalidPickTerminal = "invalid pick, an interactive terminal is required\n"
+	invalidPickStdout   = "invalid pick, cannot be used with stdout\n"
+	invalidSavePick     = "invalid save-selection, requires pick\n"
+	invalidSaveProfile  = "invalid save-selection, requires a profile the selection is saved to\n"
+	invalidGrepArgs     = "accepts a pattern and a user/repo, received %d arg(s)\n"
+	invalidGrepPattern  = "invalid pattern, received %q\n%v\n"
+	invalidGrepContext  = "invalid context, cannot be negative, received %d\n"
+	invalidCompressTTY  = "invalid compress, compressed output cannot be written to a terminal\n"
+	invalidQueryArgs    = "accepts a report and an expression, received %d arg(s)\n"
 )
 
 func validateRootArgs(args []string) error {
@@ -20,3 +38,98 @@ func validateRootArgs(args []string) error {
 
 	return nil
 }
+
+func validateProgress(mode string, stdout bool) error {
+	if !slices.Contains(progress.Modes, mode) {
+		return fmt.Errorf(invalidProgressMode, strings.Join(progress.Modes, "|"), mode)
+	}
+
+	if mode == progress.TUI && stdout {
+		return fmt.Errorf(invalidProgressTUI, mode)
+	}
+
+	return nil
+}
+
+func validateConcurrency(concurrency int) error {
+	if concurrency < 1 {
+		return fmt.Errorf(invalidConcurrency, concurrency)
+	}
+	return nil
+}
+
+func validatePick(pick, saveSelection bool, profile string, stdout, isTerminal bool) error {
+	if saveSelection && !pick {
+		return fmt.Errorf(invalidSavePick)
+	}
+	// Top-level paths would apply to every repository mapped from here
+	if saveSelection && profile == "" {
+		return fmt.Errorf(invalidSaveProfile)
+	}
+	if !pick {
+		return nil
+	}
+	if stdout {
+		return fmt.Errorf(invalidPickStdout)
+	}
+	if !isTerminal {
+		return fmt.Errorf(invalidPickTerminal)
+	}
+	return nil
+}
+
+// validateCompressStdout refuses to print compressed output to a terminal.
+func validateCompressStdout(method string, stdout, isTerminal bool) error {
+	if stdout && isTerminal && method != "" && method != compress.None {
+		return fmt.Errorf(invalidCompressTTY)
+	}
+	return nil
+}
+
+func validateGrepArgs(args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf(invalidGrepArgs, len(args))
+	}
+	return nil
+}
+
+func validateQueryArgs(args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf(invalidQueryArgs, len(args))
+	}
+	return nil
+}
+
+func validateContext(context int) error {
+	if context < 0 {
+		return fmt.Errorf(invalidGrepContext, context)
+	}
+	return nil
+}
+
+// createGrepPattern compiles a grep pattern, case-insensitive if requested.
+func createGrepPattern(pattern string, ignoreCase bool) (*regexp.Regexp, error) {
+	expr := pattern
+	if ignoreCase {
+		expr = "(?i)" + expr
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf(invalidGrepPattern, pattern, err)
+	}
+	return re, nil
+}
+
+// resolveProgress picks a concrete progress mode when auto is requested:
+// the TUI when writing a report from a terminal, plain lines otherwise.
+func resolveProgress(mode string, stdout, isTerminal bool) string {
+	if mode != progress.Auto {
+		return mode
+	}
+
+	if !stdout && isTerminal {
+		return progress.TUI
+	}
+
+	return progress.Plain
+}
@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/iamhectorsosa/octomap/internal/progress"
+	"github.com/iamhectorsosa/octomap/pkg/compress"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestValidateRootArgs(t *testing.T) {
@@ -40,3 +43,208 @@ func TestValidateRootArgs(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateProgress(t *testing.T) {
+	tests := []struct {
+		err    error
+		name   string
+		mode   string
+		stdout bool
+	}{
+		{
+			name: "Auto mode",
+			mode: "auto",
+		},
+		{
+			name:   "JSON mode with stdout",
+			mode:   "json",
+			stdout: true,
+		},
+		{
+			name: "Unknown mode",
+			mode: "fancy",
+			err:  fmt.Errorf(invalidProgressMode, "auto|tui|plain|json|none", "fancy"),
+		},
+		{
+			name:   "TUI mode with stdout",
+			mode:   "tui",
+			stdout: true,
+			err:    fmt.Errorf(invalidProgressTUI, "tui"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateProgress(tt.mode, tt.stdout)
+			if tt.err != nil {
+				assert.Error(t, err)
+				assert.EqualError(t, err, tt.err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateConcurrency(t *testing.T) {
+	tests := []struct {
+		err         error
+		name        string
+		concurrency int
+	}{
+		{
+			name:        "Valid concurrency",
+			concurrency: 4,
+		},
+		{
+			name:        "Zero concurrency",
+			concurrency: 0,
+			err:         fmt.Errorf(invalidConcurrency, 0),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateConcurrency(tt.concurrency)
+			if tt.err != nil {
+				assert.Error(t, err)
+				assert.EqualError(t, err, tt.err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidatePick(t *testing.T) {
+	tests := []struct {
+		err           error
+		name          string
+		profile       string
+		pick          bool
+		saveSelection bool
+		stdout        bool
+		isTerminal    bool
+	}{
+		{
+			name: "No pick",
+		},
+		{
+			name:          "Pick in a terminal",
+			pick:          true,
+			saveSelection: true,
+			profile:       "picked",
+			isTerminal:    true,
+		},
+		{
+			name:          "Save without pick",
+			saveSelection: true,
+			profile:       "picked",
+			isTerminal:    true,
+			err:           fmt.Errorf(invalidSavePick),
+		},
+		{
+			name:          "Save without profile",
+			pick:          true,
+			saveSelection: true,
+			isTerminal:    true,
+			err:           fmt.Errorf(invalidSaveProfile),
+		},
+		{
+			name:       "Pick with stdout",
+			pick:       true,
+			stdout:     true,
+			isTerminal: true,
+			err:        fmt.Errorf(invalidPickStdout),
+		},
+		{
+			name: "Pick without a terminal",
+			pick: true,
+			err:  fmt.Errorf(invalidPickTerminal),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validatePick(tt.pick, tt.saveSelection, tt.profile, tt.stdout, tt.isTerminal)
+			if tt.err != nil {
+				assert.Error(t, err)
+				assert.EqualError(t, err, tt.err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateGrepArgs(t *testing.T) {
+	assert.NoError(t, validateGrepArgs([]string{"TODO", "user/repo"}))
+	assert.EqualError(t, validateGrepArgs([]string{"TODO"}), fmt.Sprintf(invalidGrepArgs, 1))
+	assert.NoError(t, validateContext(2))
+	assert.EqualError(t, validateContext(-1), fmt.Sprintf(invalidGrepContext, -1))
+}
+
+func TestValidateCompressStdout(t *testing.T) {
+	assert.NoError(t, validateCompressStdout(compress.Gzip, false, true))
+	assert.NoError(t, validateCompressStdout(compress.Gzip, true, false))
+	assert.NoError(t, validateCompressStdout(compress.None, true, true))
+	assert.EqualError(t, validateCompressStdout(compress.Zstd, true, true), invalidCompressTTY)
+}
+
+func TestValidateQueryArgs(t *testing.T) {
+	assert.NoError(t, validateQueryArgs([]string{"report.json", "size > 1k"}))
+	assert.EqualError(t, validateQueryArgs([]string{"report.json"}), fmt.Sprintf(invalidQueryArgs, 1))
+}
+
+func TestCreateGrepPattern(t *testing.T) {
+	re, err := createGrepPattern("todo", true)
+	require.NoError(t, err)
+	assert.True(t, re.MatchString("// TODO"))
+
+	re, err = createGrepPattern("todo", false)
+	require.NoError(t, err)
+	assert.False(t, re.MatchString("// TODO"))
+
+	_, err = createGrepPattern("(", false)
+	assert.Error(t, err)
+}
+
+func TestResolveProgress(t *testing.T) {
+	tests := []struct {
+		name       string
+		mode       string
+		want       string
+		stdout     bool
+		isTerminal bool
+	}{
+		{
+			name:       "Auto in a terminal",
+			mode:       progress.Auto,
+			isTerminal: true,
+			want:       progress.TUI,
+		},
+		{
+			name:       "Auto in a terminal with stdout",
+			mode:       progress.Auto,
+			stdout:     true,
+			isTerminal: true,
+			want:       progress.Plain,
+		},
+		{
+			name: "Auto without a terminal",
+			mode: progress.Auto,
+			want: progress.Plain,
+		},
+		{
+			name: "Explicit mode",
+			mode: progress.JSON,
+			want: progress.JSON,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, resolveProgress(tt.mode, tt.stdout, tt.isTerminal))
+		})
+	}
+}
@@ -0,0 +1,374 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	githubRaw = "https://raw.githubusercontent.com/%s/%s/%s"
+
+	errReadFile     = "failed to read config file: %q\n%v\n"
+	errDecodeFile   = "failed to decode config file: %q\n%v\n"
+	warnFetchFile   = "warning: ignoring repository config file: %q\n%v\n"
+	invalidProfile  = "invalid profile, not found in any config file, received %q\n"
+	invalidListItem = "invalid list, expected strings, received %v\n"
+)
+
+// FileNames lists the config file names looked up in every location, in order.
+var FileNames = []string{
+	"octomap.yaml",
+	"octomap.yml",
+	".octomap.yaml",
+	".octomap.yml",
+	"octomap.toml",
+	".octomap.toml",
+}
+
+// RawURL is the location repository config files are fetched from.
+var RawURL = githubRaw
+
+// Warnings receives the problems that do not stop a command, such as a
+// repository config file that cannot be fetched.
+var Warnings io.Writer = os.Stderr
+
+var fetchClient = &http.Client{Timeout: 10 * time.Second}
+
+// Values holds the settings a config file or profile can provide. Nil fields
+// were not set and fall through to the next source.
+type Values struct {
+	Branch   *string `yaml:"branch" toml:"branch"`
+	Output   *string `yaml:"output" toml:"output"`
+	Conflict *string `yaml:"on-conflict" toml:"on-conflict"`
+	Progress *string `yaml:"progress" toml:"progress"`
+	Mode     *string `yaml:"mode" toml:"mode"`
+	Redact   *string `yaml:"redact" toml:"redact"`
+	Format   *string `yaml:"format" toml:"format"`
+	Compress *string `yaml:"compress" toml:"compress"`
+	LFS      *string `yaml:"lfs" toml:"lfs"`
+	Jobs     *int    `yaml:"jobs" toml:"jobs"`
+	MaxSize  *int    `yaml:"max-size" toml:"max-size"`
+	Skipped  *bool   `yaml:"skipped" toml:"skipped"`
+	Minify   *bool   `yaml:"minify" toml:"minify"`
+	Stdout   *bool   `yaml:"stdout" toml:"stdout"`
+	MkDir    *bool   `yaml:"mkdir" toml:"mkdir"`
+	TreeOnly *bool   `yaml:"tree-only" toml:"tree-only"`
+	Symlinks *bool   `yaml:"follow-symlinks" toml:"follow-symlinks"`
+	Ignore   *bool   `yaml:"gitignore" toml:"gitignore"`
+	Remote   *bool   `yaml:"repo-config" toml:"repo-config"`
+
+	Deterministic *bool    `yaml:"deterministic" toml:"deterministic"`
+	Submodules    *string  `yaml:"submodules" toml:"submodules"`
+	Depth         *int     `yaml:"submodule-depth" toml:"submodule-depth"`
+	TreeFormat    *string  `yaml:"tree-format" toml:"tree-format"`
+	Contains      *string  `yaml:"contains" toml:"contains"`
+	Dir           List     `yaml:"dir" toml:"dir"`
+	Full          []string `yaml:"full" toml:"full"`
+	Include       []string `yaml:"include" toml:"include"`
+	Exclude       []string `yaml:"exclude" toml:"exclude"`
+	Paths         []string `yaml:"paths" toml:"paths"`
+	Lang          []string `yaml:"lang" toml:"lang"`
+
+	RedactPatterns []string `yaml:"redact-pattern" toml:"redact-pattern"`
+}
+
+// List is a list of strings that can also be written as a single string.
+type List []string
+
+func (l *List) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*l = List{node.Value}
+		return nil
+	}
+	var values []string
+	if err := node.Decode(&values); err != nil {
+		return err
+	}
+	*l = values
+	return nil
+}
+
+func (l *List) UnmarshalTOML(data any) error {
+	switch v := data.(type) {
+	case string:
+		*l = List{v}
+	case []any:
+		values := make(List, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return fmt.Errorf(invalidListItem, item)
+			}
+			values = append(values, s)
+		}
+		*l = values
+	default:
+		return fmt.Errorf(invalidListItem, data)
+	}
+	return nil
+}
+
+// File is a decoded config file: top-level values plus named profiles.
+type File struct {
+	Values   `yaml:",inline"`
+	Profiles map[string]Values `yaml:"profiles" toml:"profiles"`
+	Path     string            `yaml:"-" toml:"-"`
+}
+
+// Decode parses a YAML or TOML config file depending on its name.
+func Decode(name string, content []byte) (*File, error) {
+	var f File
+	var err error
+
+	if strings.HasSuffix(name, ".toml") {
+		_, err = toml.NewDecoder(bytes.NewReader(content)).Decode(&f)
+	} else {
+		err = yaml.Unmarshal(content, &f)
+	}
+	if err != nil {
+		return nil, fmt.Errorf(errDecodeFile, name, err)
+	}
+
+	f.Path = name
+	return &f, nil
+}
+
+// Find returns the first config file found in dir, or nil if there is none.
+func Find(dir string) (*File, error) {
+	if dir == "" {
+		return nil, nil
+	}
+
+	for _, name := range FileNames {
+		path := filepath.Join(dir, name)
+		content, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf(errReadFile, path, err)
+		}
+		return Decode(path, content)
+	}
+
+	return nil, nil
+}
+
+// Fetch returns the first config file found at the root of the repository
+// branch, or nil if there is none. Names are tried in order until a response
+// other than 404 Not Found. A repository config is optional, so failed
+// requests and other responses are reported to Warnings and treated as a
+// missing file, leaving an invalid slug to be reported when the archive is
+// downloaded.
+func Fetch(slug, branch string) (*File, error) {
+	for _, name := range FileNames {
+		url := fmt.Sprintf(RawURL, slug, branch, name)
+
+		resp, err := fetchClient.Get(url)
+		if err != nil {
+			fmt.Fprintf(Warnings, warnFetchFile, url, err)
+			return nil, nil
+		}
+		if resp.StatusCode == http.StatusNotFound {
+			resp.Body.Close()
+			continue
+		}
+
+		content, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			fmt.Fprintf(Warnings, warnFetchFile, url, err)
+			return nil, nil
+		}
+		if resp.StatusCode != http.StatusOK {
+			fmt.Fprintf(Warnings, warnFetchFile, url, fmt.Errorf("unexpected status code: %d", resp.StatusCode))
+			return nil, nil
+		}
+		return Decode(url, content)
+	}
+
+	return nil, nil
+}
+
+// UserDir returns $XDG_CONFIG_HOME/octomap, falling back to ~/.config/octomap.
+func UserDir() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "octomap")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "octomap")
+}
+
+// Resolve returns the file's top-level values overridden by the given
+// profile, and whether the profile was found.
+func (f *File) Resolve(profile string) (Values, bool) {
+	if f == nil {
+		return Values{}, false
+	}
+	if profile == "" {
+		return f.Values, false
+	}
+	p, ok := f.Profiles[profile]
+	if !ok {
+		return f.Values, false
+	}
+	return Merge(f.Values, p), true
+}
+
+// Merge returns base with every field set in override replaced.
+func Merge(base, override Values) Values {
+	if override.Branch != nil {
+		base.Branch = override.Branch
+	}
+	if override.Dir != nil {
+		base.Dir = override.Dir
+	}
+	if override.Output != nil {
+		base.Output = override.Output
+	}
+	if override.Progress != nil {
+		base.Progress = override.Progress
+	}
+	if override.Jobs != nil {
+		base.Jobs = override.Jobs
+	}
+	if override.MaxSize != nil {
+		base.MaxSize = override.MaxSize
+	}
+	if override.Skipped != nil {
+		base.Skipped = override.Skipped
+	}
+	if override.Stdout != nil {
+		base.Stdout = override.Stdout
+	}
+	if override.Mode != nil {
+		base.Mode = override.Mode
+	}
+	if override.TreeOnly != nil {
+		base.TreeOnly = override.TreeOnly
+	}
+	if override.TreeFormat != nil {
+		base.TreeFormat = override.TreeFormat
+	}
+	if override.Conflict != nil {
+		base.Conflict = override.Conflict
+	}
+	if override.MkDir != nil {
+		base.MkDir = override.MkDir
+	}
+	if override.Deterministic != nil {
+		base.Deterministic = override.Deterministic
+	}
+	if override.LFS != nil {
+		base.LFS = override.LFS
+	}
+	if override.Submodules != nil {
+		base.Submodules = override.Submodules
+	}
+	if override.Depth != nil {
+		base.Depth = override.Depth
+	}
+	if override.Symlinks != nil {
+		base.Symlinks = override.Symlinks
+	}
+	if override.Ignore != nil {
+		base.Ignore = override.Ignore
+	}
+	if override.Remote != nil {
+		base.Remote = override.Remote
+	}
+	if override.Compress != nil {
+		base.Compress = override.Compress
+	}
+	if override.Format != nil {
+		base.Format = override.Format
+	}
+	if override.Contains != nil {
+		base.Contains = override.Contains
+	}
+	if override.Minify != nil {
+		base.Minify = override.Minify
+	}
+	if override.Full != nil {
+		base.Full = override.Full
+	}
+	if override.Redact != nil {
+		base.Redact = override.Redact
+	}
+	if override.RedactPatterns != nil {
+		base.RedactPatterns = override.RedactPatterns
+	}
+	if override.Include != nil {
+		base.Include = override.Include
+	}
+	if override.Exclude != nil {
+		base.Exclude = override.Exclude
+	}
+	if override.Paths != nil {
+		base.Paths = override.Paths
+	}
+	if override.Lang != nil {
+		base.Lang = override.Lang
+	}
+	return base
+}
+
+// Load merges the given files in increasing order of precedence, applying
+// profile on top of each file's top-level values. A non-empty profile must
+// exist in at least one file.
+func Load(profile string, files ...*File) (Values, error) {
+	var values Values
+	found := profile == ""
+
+	for _, f := range files {
+		v, ok := f.Resolve(profile)
+		found = found || ok
+		values = Merge(values, v)
+	}
+
+	if !found {
+		return Values{}, fmt.Errorf(invalidProfile, profile)
+	}
+	return values, nil
+}
+
+// Restricted returns a copy of the file without the settings a repository
+// config file may not control: which branch is mapped, where reports are
+// written and how secrets are redacted.
+func (f *File) Restricted() *File {
+	if f == nil {
+		return nil
+	}
+
+	restricted := *f
+	restricted.Values = restrict(f.Values)
+	restricted.Profiles = make(map[string]Values, len(f.Profiles))
+	for name, p := range f.Profiles {
+		restricted.Profiles[name] = restrict(p)
+	}
+	return &restricted
+}
+
+func restrict(v Values) Values {
+	v.Branch = nil
+	v.Output = nil
+	v.Conflict = nil
+	v.MkDir = nil
+	v.Stdout = nil
+	v.Redact = nil
+	v.RedactPatterns = nil
+	v.Remote = nil
+	return v
+}
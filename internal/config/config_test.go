@@ -0,0 +1,266 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const yamlConfig = `
+branch: develop
+include: [.go, .mod]
+jobs: 4
+profiles:
+  go-backend:
+    dir: api
+    exclude: [_test.go]
+`
+
+const tomlConfig = `
+branch = "develop"
+include = [".go", ".mod"]
+jobs = 4
+
+[profiles.go-backend]
+dir = "api"
+exclude = ["_test.go"]
+`
+
+func ptr[T any](v T) *T { return &v }
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{
+			name:    "YAML file",
+			file:    "octomap.yaml",
+			content: yamlConfig,
+		},
+		{
+			name:    "TOML file",
+			file:    ".octomap.toml",
+			content: tomlConfig,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := Decode(tt.file, []byte(tt.content))
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.file, f.Path)
+			assert.Equal(t, ptr("develop"), f.Branch)
+			assert.Equal(t, []string{".go", ".mod"}, f.Include)
+			assert.Equal(t, ptr(4), f.Jobs)
+			assert.Nil(t, f.Dir)
+
+			values, ok := f.Resolve("go-backend")
+			assert.True(t, ok)
+			assert.Equal(t, List{"api"}, values.Dir)
+			assert.Equal(t, []string{"_test.go"}, values.Exclude)
+			assert.Equal(t, []string{".go", ".mod"}, values.Include)
+		})
+	}
+}
+
+func TestDecodeError(t *testing.T) {
+	_, err := Decode("octomap.yaml", []byte("include: ["))
+	assert.Error(t, err)
+}
+
+func TestFind(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	f, err := Find(tmpDir)
+	require.NoError(t, err)
+	assert.Nil(t, f)
+
+	path := filepath.Join(tmpDir, ".octomap.toml")
+	require.NoError(t, os.WriteFile(path, []byte(tomlConfig), 0644))
+
+	f, err = Find(tmpDir)
+	require.NoError(t, err)
+	require.NotNil(t, f)
+	assert.Equal(t, path, f.Path)
+}
+
+func TestFetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/user/repo/main/octomap.yml":
+			fmt.Fprint(w, yamlConfig)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	origRawURL := RawURL
+	RawURL = server.URL + "/%s/%s/%s"
+	defer func() { RawURL = origRawURL }()
+
+	f, err := Fetch("user/repo", "main")
+	require.NoError(t, err)
+	require.NotNil(t, f)
+	assert.Equal(t, ptr("develop"), f.Branch)
+
+	f, err = Fetch("user/repo", "develop")
+	require.NoError(t, err)
+	assert.Nil(t, f)
+}
+
+func TestFetchWarnings(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path)
+		switch r.URL.Path {
+		case "/user/repo/main/octomap.yml":
+			http.Error(w, "rate limited", http.StatusTooManyRequests)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	origRawURL, origWarnings := RawURL, Warnings
+	RawURL = server.URL + "/%s/%s/%s"
+	var warnings bytes.Buffer
+	Warnings = &warnings
+	defer func() { RawURL, Warnings = origRawURL, origWarnings }()
+
+	f, err := Fetch("user/repo", "main")
+	require.NoError(t, err)
+	assert.Nil(t, f)
+	assert.Equal(t, []string{"/user/repo/main/octomap.yaml", "/user/repo/main/octomap.yml"}, requests)
+	assert.Contains(t, warnings.String(), "unexpected status code: 429")
+
+	server.Close()
+	warnings.Reset()
+	f, err = Fetch("user/repo", "main")
+	require.NoError(t, err)
+	assert.Nil(t, f)
+	assert.Contains(t, warnings.String(), "warning: ignoring repository config file")
+}
+
+func TestLoad(t *testing.T) {
+	user := &File{
+		Values: Values{Branch: ptr("develop"), Include: []string{".go"}},
+		Profiles: map[string]Values{
+			"docs": {Include: []string{".md"}},
+		},
+	}
+	repo := &File{
+		Values: Values{Dir: List{"src"}, Output: ptr("/tmp"), MkDir: ptr(true), Conflict: ptr("overwrite")},
+		Profiles: map[string]Values{
+			"go-backend": {Dir: List{"api"}, Output: ptr("/tmp")},
+		},
+	}
+	local := &File{
+		Values: Values{Jobs: ptr(2)},
+	}
+
+	tests := []struct {
+		err     error
+		want    Values
+		name    string
+		profile string
+	}{
+		{
+			name: "Without profile",
+			want: Values{
+				Branch:  ptr("develop"),
+				Dir:     List{"src"},
+				Jobs:    ptr(2),
+				Include: []string{".go"},
+			},
+		},
+		{
+			name:    "Profile from the repository",
+			profile: "go-backend",
+			want: Values{
+				Branch:  ptr("develop"),
+				Dir:     List{"api"},
+				Jobs:    ptr(2),
+				Include: []string{".go"},
+			},
+		},
+		{
+			name:    "Profile from the user directory",
+			profile: "docs",
+			want: Values{
+				Branch:  ptr("develop"),
+				Dir:     List{"src"},
+				Jobs:    ptr(2),
+				Include: []string{".md"},
+			},
+		},
+		{
+			name:    "Unknown profile",
+			profile: "frontend",
+			err:     fmt.Errorf(invalidProfile, "frontend"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Load(tt.profile, user, repo.Restricted(), nil, local)
+			if tt.err != nil {
+				assert.EqualError(t, err, tt.err.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestList(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    List
+	}{
+		{
+			name:    "YAML string",
+			file:    "octomap.yaml",
+			content: "dir: api",
+			want:    List{"api"},
+		},
+		{
+			name:    "YAML list",
+			file:    "octomap.yaml",
+			content: "dir: [api, pkg/auth]",
+			want:    List{"api", "pkg/auth"},
+		},
+		{
+			name:    "TOML string",
+			file:    "octomap.toml",
+			content: `dir = "api"`,
+			want:    List{"api"},
+		},
+		{
+			name:    "TOML list",
+			file:    "octomap.toml",
+			content: `dir = ["api", "pkg/auth"]`,
+			want:    List{"api", "pkg/auth"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := Decode(tt.file, []byte(tt.content))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, f.Dir)
+		})
+	}
+}
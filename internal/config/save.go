@@ -0,0 +1,163 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	errWriteFile     = "failed to write config file: %q\n%v\n"
+	invalidStructure = "invalid config file, expected a mapping at %q\n"
+)
+
+// SavePaths sets the paths list of the first config file found in dir, or of
+// a new octomap.yaml, and returns the path written. With a profile the list
+// is set on that profile instead. Other settings are kept, as are comments in
+// YAML files.
+func SavePaths(dir, profile string, paths []string) (string, error) {
+	path := filepath.Join(dir, FileNames[0])
+	for _, name := range FileNames {
+		candidate := filepath.Join(dir, name)
+		if _, err := os.Stat(candidate); err == nil {
+			path = candidate
+			break
+		}
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf(errReadFile, path, err)
+	}
+
+	if strings.HasSuffix(path, ".toml") {
+		content, err = setTOMLPaths(content, profile, paths)
+	} else {
+		content, err = setYAMLPaths(content, profile, paths)
+	}
+	if err != nil {
+		return "", fmt.Errorf(errDecodeFile, path, err)
+	}
+
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		return "", fmt.Errorf(errWriteFile, path, err)
+	}
+	return path, nil
+}
+
+func setYAMLPaths(content []byte, profile string, paths []string) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, err
+	}
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+
+	target := doc.Content[0]
+	if target.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf(invalidStructure, "/")
+	}
+	if profile != "" {
+		var err error
+		if target, err = yamlMapping(target, "profiles"); err != nil {
+			return nil, err
+		}
+		if target, err = yamlMapping(target, profile); err != nil {
+			return nil, err
+		}
+	}
+
+	list := &yaml.Node{Kind: yaml.SequenceNode}
+	for _, item := range paths {
+		list.Content = append(list.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: item})
+	}
+	setYAMLKey(target, "paths", list)
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// yamlMapping returns the mapping under key, adding an empty one if missing.
+func yamlMapping(node *yaml.Node, key string) (*yaml.Node, error) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value != key {
+			continue
+		}
+		value := node.Content[i+1]
+		if value.Kind == yaml.ScalarNode && value.Tag == "!!null" {
+			value.Kind, value.Tag, value.Value = yaml.MappingNode, "", ""
+		}
+		if value.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf(invalidStructure, key)
+		}
+		return value, nil
+	}
+
+	value := &yaml.Node{Kind: yaml.MappingNode}
+	setYAMLKey(node, key, value)
+	return value, nil
+}
+
+func setYAMLKey(node *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content[i+1] = value
+			return
+		}
+	}
+	node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+}
+
+func setTOMLPaths(content []byte, profile string, paths []string) ([]byte, error) {
+	values := make(map[string]any)
+	if _, err := toml.NewDecoder(bytes.NewReader(content)).Decode(&values); err != nil {
+		return nil, err
+	}
+
+	target := values
+	if profile != "" {
+		var err error
+		if target, err = tomlTable(target, "profiles"); err != nil {
+			return nil, err
+		}
+		if target, err = tomlTable(target, profile); err != nil {
+			return nil, err
+		}
+	}
+	target["paths"] = paths
+
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(values); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// tomlTable returns the table under key, adding an empty one if missing.
+func tomlTable(values map[string]any, key string) (map[string]any, error) {
+	v, ok := values[key]
+	if !ok {
+		table := make(map[string]any)
+		values[key] = table
+		return table, nil
+	}
+	table, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf(invalidStructure, key)
+	}
+	return table, nil
+}
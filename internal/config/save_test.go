@@ -0,0 +1,81 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSavePaths(t *testing.T) {
+	paths := []string{"main.go", "pkg/api.go"}
+
+	tests := []struct {
+		name     string
+		fileName string
+		content  string
+		profile  string
+		want     Values
+	}{
+		{
+			name:     "new file",
+			fileName: "octomap.yaml",
+			want:     Values{Paths: paths},
+		},
+		{
+			name:     "yaml top-level",
+			fileName: "octomap.yaml",
+			content:  "# keep me\n" + yamlConfig,
+			want:     Values{Branch: ptr("develop"), Jobs: ptr(4), Include: []string{".go", ".mod"}, Paths: paths},
+		},
+		{
+			name:     "yaml profile",
+			fileName: ".octomap.yml",
+			content:  yamlConfig,
+			profile:  "go-backend",
+			want: Values{
+				Branch:  ptr("develop"),
+				Jobs:    ptr(4),
+				Dir:     List{"api"},
+				Include: []string{".go", ".mod"},
+				Exclude: []string{"_test.go"},
+				Paths:   paths,
+			},
+		},
+		{
+			name:     "toml new profile",
+			fileName: "octomap.toml",
+			content:  tomlConfig,
+			profile:  "picked",
+			want:     Values{Branch: ptr("develop"), Jobs: ptr(4), Include: []string{".go", ".mod"}, Paths: paths},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			want := filepath.Join(tmpDir, tt.fileName)
+			if tt.content != "" {
+				require.NoError(t, os.WriteFile(want, []byte(tt.content), 0644))
+			}
+
+			path, err := SavePaths(tmpDir, tt.profile, paths)
+			require.NoError(t, err)
+			assert.Equal(t, want, path)
+
+			f, err := Find(tmpDir)
+			require.NoError(t, err)
+			values, err := Load(tt.profile, f)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, values)
+
+			if tt.content != "" && tt.fileName == "octomap.yaml" {
+				content, err := os.ReadFile(path)
+				require.NoError(t, err)
+				assert.Contains(t, string(content), "# keep me")
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+// Package mcp serves octomap as a Model Context Protocol server over stdio,
+// so agents can map a repository once and explore it with small tool calls.
+package mcp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/iamhectorsosa/octomap/pkg/processor"
+)
+
+const (
+	protocolVersion = "2024-11-05"
+	jsonrpcVersion  = "2.0"
+
+	// JSON-RPC error codes
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+
+	errUnknownMethod = "unknown method: %q"
+	errUnknownTool   = "unknown tool: %q"
+)
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Message string `json:"message"`
+	Code    int    `json:"code"`
+}
+
+type content struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type toolResult struct {
+	Content []content `json:"content"`
+	IsError bool      `json:"isError,omitempty"`
+}
+
+// Server answers MCP requests. Mapped repositories are cached for the
+// lifetime of the server, keyed by repository and branch.
+type Server struct {
+	cache map[string]*mapping
+
+	// newConfig resolves the processor config of a mapping
+	newConfig func(processor.Options) (*processor.Config, error)
+
+	mu sync.Mutex
+}
+
+func NewServer() *Server {
+	return &Server{
+		cache:     make(map[string]*mapping),
+		newConfig: processor.NewConfig,
+	}
+}
+
+// Serve reads newline-delimited JSON-RPC messages from r and writes the
+// responses to w until r is exhausted.
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	reader := bufio.NewReader(r)
+	encoder := json.NewEncoder(w)
+
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			if resp := s.handle(line); resp != nil {
+				if err := encoder.Encode(resp); err != nil {
+					return err
+				}
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// handle answers one message, returning nil for notifications.
+func (s *Server) handle(line []byte) *response {
+	var req request
+	if err := json.Unmarshal(line, &req); err != nil {
+		return errorResponse(json.RawMessage("null"), codeParseError, err.Error())
+	}
+	if req.JSONRPC != jsonrpcVersion || req.Method == "" {
+		return errorResponse(req.ID, codeInvalidRequest, "invalid request")
+	}
+
+	// Notifications carry no id and expect no response
+	if len(req.ID) == 0 {
+		return nil
+	}
+
+	switch req.Method {
+	case "initialize":
+		return resultResponse(req.ID, map[string]any{
+			"protocolVersion": protocolVersion,
+			"capabilities":    map[string]any{"tools": map[string]any{}},
+			"serverInfo":      map[string]any{"name": "octomap", "version": "1.0.0"},
+		})
+	case "ping":
+		return resultResponse(req.ID, map[string]any{})
+	case "tools/list":
+		return resultResponse(req.ID, map[string]any{"tools": tools})
+	case "tools/call":
+		var params struct {
+			Name      string          `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return errorResponse(req.ID, codeInvalidParams, err.Error())
+		}
+		handler, ok := s.handlers()[params.Name]
+		if !ok {
+			return errorResponse(req.ID, codeInvalidParams, fmt.Sprintf(errUnknownTool, params.Name))
+		}
+
+		// Tool failures are results the agent can act on, not protocol errors
+		text, err := handler(params.Arguments)
+		if err != nil {
+			return resultResponse(req.ID, toolResult{Content: []content{{Type: "text", Text: err.Error()}}, IsError: true})
+		}
+		return resultResponse(req.ID, toolResult{Content: []content{{Type: "text", Text: text}}})
+	}
+
+	return errorResponse(req.ID, codeMethodNotFound, fmt.Sprintf(errUnknownMethod, req.Method))
+}
+
+func resultResponse(id json.RawMessage, result any) *response {
+	return &response{JSONRPC: jsonrpcVersion, ID: id, Result: result}
+}
+
+func errorResponse(id json.RawMessage, code int, message string) *response {
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+	return &response{JSONRPC: jsonrpcVersion, ID: id, Error: &rpcError{Code: code, Message: message}}
+}
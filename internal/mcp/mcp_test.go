@@ -0,0 +1,131 @@
+package mcp
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/iamhectorsosa/octomap/pkg/processor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTarGz(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+
+	for name, content := range files {
+		hdr := &tar.Header{
+			Name: name,
+			Mode: 0600,
+			Size: int64(len(content)),
+		}
+		require.NoError(t, tw.WriteHeader(hdr))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, tw.Close())
+	require.NoError(t, gw.Close())
+	return buf.Bytes()
+}
+
+// newTestServer returns a server mapping every repository from archive,
+// counting the downloads.
+func newTestServer(t *testing.T, archive []byte, downloads *int) *Server {
+	t.Helper()
+
+	archiveServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*downloads++
+		w.Write(archive)
+	}))
+	t.Cleanup(archiveServer.Close)
+
+	s := NewServer()
+	s.newConfig = func(opts processor.Options) (*processor.Config, error) {
+		config, err := processor.NewConfig(opts)
+		if err != nil {
+			return nil, err
+		}
+		config.Url = archiveServer.URL
+		return config, nil
+	}
+	return s
+}
+
+func TestServe(t *testing.T) {
+	var downloads int
+	s := newTestServer(t, newTarGz(t, map[string]string{
+		"repo-main/main.go":        "package main\n\nfunc main() {}\n",
+		"repo-main/api/server.go":  "package api\n\n// TODO: serve\nfunc Serve() {}\n",
+		"repo-main/docs/README.md": "# Docs\n",
+	}), &downloads)
+
+	requests := []string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05"}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"list_tree","arguments":{"repo":"user/repo"}}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"read_file","arguments":{"repo":"user/repo","path":"api/server.go","start_line":3,"end_line":4}}}`,
+		`{"jsonrpc":"2.0","id":5,"method":"tools/call","params":{"name":"search_repository","arguments":{"repo":"user/repo","pattern":"todo","ignore_case":true}}}`,
+		`{"jsonrpc":"2.0","id":6,"method":"tools/call","params":{"name":"read_file","arguments":{"repo":"user/repo","path":"missing.go"}}}`,
+		`{"jsonrpc":"2.0","id":7,"method":"tools/call","params":{"name":"map_repository","arguments":{"repo":"user/repo","include":[".go"]}}}`,
+		`{"jsonrpc":"2.0","id":8,"method":"tools/call","params":{"name":"list_tree","arguments":{"repo":"user/repo"}}}`,
+		`{"jsonrpc":"2.0","id":9,"method":"resources/list"}`,
+		`not json`,
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, s.Serve(strings.NewReader(strings.Join(requests, "\n")+"\n"), &out))
+
+	type testResponse struct {
+		ID     json.RawMessage `json:"id"`
+		Error  *rpcError       `json:"error"`
+		Result struct {
+			ProtocolVersion string    `json:"protocolVersion"`
+			Tools           []tool    `json:"tools"`
+			Content         []content `json:"content"`
+			IsError         bool      `json:"isError"`
+		} `json:"result"`
+	}
+
+	var responses []testResponse
+	decoder := json.NewDecoder(&out)
+	for decoder.More() {
+		var r testResponse
+		require.NoError(t, decoder.Decode(&r))
+		responses = append(responses, r)
+	}
+
+	// The notification gets no response
+	require.Len(t, responses, 10)
+
+	assert.Equal(t, protocolVersion, responses[0].Result.ProtocolVersion)
+	assert.Len(t, responses[1].Result.Tools, 4)
+
+	assert.Equal(t, ".\n├── api/\n│   └── server.go\n├── docs/\n│   └── README.md\n└── main.go\n", responses[2].Result.Content[0].Text)
+	assert.Equal(t, "// TODO: serve\nfunc Serve() {}\n", responses[3].Result.Content[0].Text)
+	assert.Equal(t, "api/server.go:3: // TODO: serve\n", responses[4].Result.Content[0].Text)
+
+	assert.True(t, responses[5].Result.IsError)
+	assert.Contains(t, responses[5].Result.Content[0].Text, "missing.go")
+
+	assert.Contains(t, responses[6].Result.Content[0].Text, "mapped user/repo@main: 2 files")
+	assert.Equal(t, ".\n├── api/\n│   └── server.go\n└── main.go\n", responses[7].Result.Content[0].Text)
+
+	require.NotNil(t, responses[8].Error)
+	assert.Equal(t, codeMethodNotFound, responses[8].Error.Code)
+	require.NotNil(t, responses[9].Error)
+	assert.Equal(t, codeParseError, responses[9].Error.Code)
+
+	// Tools reuse the mapping until the repository is mapped again
+	assert.Equal(t, 2, downloads)
+}
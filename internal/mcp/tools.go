@@ -0,0 +1,369 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/iamhectorsosa/octomap/internal/progress"
+	"github.com/iamhectorsosa/octomap/pkg/processor"
+)
+
+const (
+	defaultBranch     = "main"
+	defaultMaxResults = 100
+
+	errNotFound     = "not found in %s: %q"
+	errNotFile      = "not a file: %q"
+	errNotDirectory = "not a directory: %q"
+	errLineRange    = "invalid line range, received %d to %d"
+	errPattern      = "invalid pattern, received %q: %v"
+)
+
+type tool struct {
+	InputSchema map[string]any `json:"inputSchema"`
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+}
+
+func schema(required []string, properties map[string]any) map[string]any {
+	return map[string]any{"type": "object", "properties": properties, "required": required}
+}
+
+func property(kind, description string) map[string]any {
+	return map[string]any{"type": kind, "description": description}
+}
+
+func listProperty(description string) map[string]any {
+	return map[string]any{"type": "array", "items": map[string]any{"type": "string"}, "description": description}
+}
+
+var (
+	repoProperty   = property("string", "GitHub repository as user/repo")
+	branchProperty = property("string", "Branch to map, main by default")
+)
+
+var tools = []tool{
+	{
+		Name: "map_repository",
+		Description: "Map a GitHub repository and return per-language statistics. " +
+			"The mapping is kept for list_tree, read_file and search_repository, which map the repository " +
+			"with default options when it was not mapped yet. Mapping again replaces it.",
+		InputSchema: schema([]string{"repo"}, map[string]any{
+			"repo":    repoProperty,
+			"branch":  branchProperty,
+			"dir":     listProperty("Directories within the repository to map"),
+			"include": listProperty("Included file extensions, e.g. .go"),
+			"exclude": listProperty("Excluded file extensions, e.g. _test.go"),
+			"lang":    listProperty("Included languages, e.g. go"),
+			"mode":    property("string", "Content mode: full or outline"),
+			"minify":  property("boolean", "Strip comments and redundant blank lines"),
+			"redact":  property("string", "Secret redaction: none, mask or drop-file"),
+		}),
+	},
+	{
+		Name:        "list_tree",
+		Description: "List the directory tree of a mapped repository, optionally below a path.",
+		InputSchema: schema([]string{"repo"}, map[string]any{
+			"repo":   repoProperty,
+			"branch": branchProperty,
+			"path":   property("string", "Directory to list, the repository root by default"),
+		}),
+	},
+	{
+		Name:        "read_file",
+		Description: "Read a file of a mapped repository, optionally a range of lines.",
+		InputSchema: schema([]string{"repo", "path"}, map[string]any{
+			"repo":       repoProperty,
+			"branch":     branchProperty,
+			"path":       property("string", "File path as listed by list_tree"),
+			"start_line": property("integer", "First line to read, starting at 1"),
+			"end_line":   property("integer", "Last line to read, inclusive"),
+		}),
+	},
+	{
+		Name:        "search_repository",
+		Description: "Search the files of a mapped repository with a regular expression, returning path:line: text matches.",
+		InputSchema: schema([]string{"repo", "pattern"}, map[string]any{
+			"repo":        repoProperty,
+			"branch":      branchProperty,
+			"pattern":     property("string", "Regular expression matched against each line"),
+			"path":        property("string", "Only search files below this path"),
+			"ignore_case": property("boolean", "Match case-insensitively"),
+			"max_results": property("integer", "Maximum number of matches, 100 by default"),
+		}),
+	},
+}
+
+type repoArgs struct {
+	Repo   string `json:"repo"`
+	Branch string `json:"branch"`
+}
+
+func (a repoArgs) key() string {
+	branch := a.Branch
+	if branch == "" {
+		branch = defaultBranch
+	}
+	return a.Repo + "@" + branch
+}
+
+type mapArgs struct {
+	repoArgs
+	Mode    string   `json:"mode"`
+	Redact  string   `json:"redact"`
+	Dir     []string `json:"dir"`
+	Include []string `json:"include"`
+	Exclude []string `json:"exclude"`
+	Lang    []string `json:"lang"`
+	Minify  bool     `json:"minify"`
+}
+
+// mapping is a mapped repository kept by the server.
+type mapping struct {
+	data  processor.RepositoryData
+	stats processor.Stats
+}
+
+func (s *Server) handlers() map[string]func(json.RawMessage) (string, error) {
+	return map[string]func(json.RawMessage) (string, error){
+		"map_repository":    s.mapRepository,
+		"list_tree":         s.listTree,
+		"read_file":         s.readFile,
+		"search_repository": s.searchRepository,
+	}
+}
+
+// mapRepo maps a repository without writing a report and caches the result.
+func (s *Server) mapRepo(args mapArgs) (*mapping, error) {
+	branch := args.Branch
+	if branch == "" {
+		branch = defaultBranch
+	}
+	config, err := s.newConfig(processor.Options{
+		Slug:    args.Repo,
+		Branch:  branch,
+		Dirs:    args.Dir,
+		Include: args.Include,
+		Exclude: args.Exclude,
+		Langs:   args.Lang,
+		Mode:    args.Mode,
+		Minify:  args.Minify,
+		Redact:  args.Redact,
+		Stdout:  true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	p := processor.New(config, nil)
+	data, err := p.Process()
+	if err != nil {
+		return nil, err
+	}
+
+	m := &mapping{data: data, stats: p.Stats()}
+	s.mu.Lock()
+	s.cache[args.key()] = m
+	s.mu.Unlock()
+	return m, nil
+}
+
+// mapped returns the cached mapping of a repository, mapping it with default
+// options when needed.
+func (s *Server) mapped(args repoArgs) (*mapping, error) {
+	s.mu.Lock()
+	m, ok := s.cache[args.key()]
+	s.mu.Unlock()
+	if ok {
+		return m, nil
+	}
+	return s.mapRepo(mapArgs{repoArgs: args})
+}
+
+func (s *Server) mapRepository(raw json.RawMessage) (string, error) {
+	var args mapArgs
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return "", err
+	}
+	m, err := s.mapRepo(args)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "mapped %s: %d files, %d bytes, ~%d tokens\n", args.key(), m.stats.Files, m.stats.Bytes, m.stats.Tokens)
+	for _, l := range m.stats.Languages {
+		b.WriteString(progress.FormatLanguage(l) + "\n")
+	}
+	for _, line := range progress.FormatSkipped(m.stats.Skipped) {
+		b.WriteString(line + "\n")
+	}
+	return b.String(), nil
+}
+
+func (s *Server) listTree(raw json.RawMessage) (string, error) {
+	var args struct {
+		repoArgs
+		Path string `json:"path"`
+	}
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return "", err
+	}
+	m, err := s.mapped(args.repoArgs)
+	if err != nil {
+		return "", err
+	}
+
+	node, err := lookup(m.data, args.key(), args.Path)
+	if err != nil {
+		return "", err
+	}
+	dir, ok := node.(map[string]interface{})
+	if !ok {
+		return "", fmt.Errorf(errNotDirectory, args.Path)
+	}
+	return processor.RenderTree(dir), nil
+}
+
+func (s *Server) readFile(raw json.RawMessage) (string, error) {
+	var args struct {
+		repoArgs
+		Path      string `json:"path"`
+		StartLine int    `json:"start_line"`
+		EndLine   int    `json:"end_line"`
+	}
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return "", err
+	}
+	m, err := s.mapped(args.repoArgs)
+	if err != nil {
+		return "", err
+	}
+
+	node, err := lookup(m.data, args.key(), args.Path)
+	if err != nil {
+		return "", err
+	}
+	file, ok := node.(string)
+	if !ok {
+		return "", fmt.Errorf(errNotFile, args.Path)
+	}
+	if args.StartLine == 0 && args.EndLine == 0 {
+		return file, nil
+	}
+
+	lines := strings.SplitAfter(file, "\n")
+	start, end := max(args.StartLine, 1), args.EndLine
+	if end == 0 || end > len(lines) {
+		end = len(lines)
+	}
+	if start > end {
+		return "", fmt.Errorf(errLineRange, args.StartLine, args.EndLine)
+	}
+	return strings.Join(lines[start-1:end], ""), nil
+}
+
+func (s *Server) searchRepository(raw json.RawMessage) (string, error) {
+	var args struct {
+		repoArgs
+		Pattern    string `json:"pattern"`
+		Path       string `json:"path"`
+		IgnoreCase bool   `json:"ignore_case"`
+		MaxResults int    `json:"max_results"`
+	}
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return "", err
+	}
+
+	pattern := args.Pattern
+	if args.IgnoreCase {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return "", fmt.Errorf(errPattern, args.Pattern, err)
+	}
+	maxResults := args.MaxResults
+	if maxResults <= 0 {
+		maxResults = defaultMaxResults
+	}
+
+	m, err := s.mapped(args.repoArgs)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	matches := 0
+	prefix := strings.Trim(args.Path, "/")
+	for _, f := range files(m.data, "") {
+		if prefix != "" && f.path != prefix && !strings.HasPrefix(f.path, prefix+"/") {
+			continue
+		}
+		for i, line := range strings.Split(f.content, "\n") {
+			if !re.MatchString(line) {
+				continue
+			}
+			if matches == maxResults {
+				fmt.Fprintf(&b, "stopped after %d matches\n", maxResults)
+				return b.String(), nil
+			}
+			fmt.Fprintf(&b, "%s:%d: %s\n", f.path, i+1, line)
+			matches++
+		}
+	}
+
+	if matches == 0 {
+		return "no matches\n", nil
+	}
+	return b.String(), nil
+}
+
+// lookup returns the directory or file content at path, the root for an
+// empty path.
+func lookup(data processor.RepositoryData, repo, path string) (interface{}, error) {
+	var node interface{} = map[string]interface{}(data)
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return node, nil
+	}
+
+	for _, part := range strings.Split(path, "/") {
+		dir, ok := node.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf(errNotFound, repo, path)
+		}
+		if node, ok = dir[part]; !ok {
+			return nil, fmt.Errorf(errNotFound, repo, path)
+		}
+	}
+	return node, nil
+}
+
+type file struct {
+	path    string
+	content string
+}
+
+// files flattens repository data into files ordered by path.
+func files(node map[string]interface{}, prefix string) []file {
+	names := make([]string, 0, len(node))
+	for name := range node {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var result []file
+	for _, name := range names {
+		switch v := node[name].(type) {
+		case map[string]interface{}:
+			result = append(result, files(v, prefix+name+"/")...)
+		case string:
+			result = append(result, file{path: prefix + name, content: v})
+		}
+	}
+	return result
+}
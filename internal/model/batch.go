@@ -0,0 +1,136 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/iamhectorsosa/octomap/internal/batch"
+)
+
+var pendingMark = lipgloss.NewStyle().Foreground(lipgloss.Color("241")).SetString("·")
+
+type row struct {
+	err         error
+	slug        string
+	description string
+	status      batch.Status
+}
+
+type batchModel struct {
+	eventsCh <-chan batch.Event
+	rows     []row
+	spinner  spinner.Model
+	complete bool
+}
+
+func NewBatch(slugs []string, eventsCh <-chan batch.Event) batchModel {
+	sp := spinner.New()
+	sp.Spinner = spinner.Dot
+	sp.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
+
+	rows := make([]row, len(slugs))
+	for i, slug := range slugs {
+		rows[i] = row{slug: slug, status: batch.Pending}
+	}
+
+	return batchModel{
+		eventsCh: eventsCh,
+		rows:     rows,
+		spinner:  sp,
+	}
+}
+
+func (m batchModel) Init() tea.Cmd {
+	return tea.Batch(m.spinner.Tick, m.updateBatch())
+}
+
+type batchMsg []batch.Event
+
+// updateBatch drains every event received since the previous render tick.
+func (m batchModel) updateBatch() tea.Cmd {
+	return tea.Tick(renderInterval, func(time.Time) tea.Msg {
+		var events []batch.Event
+		for {
+			select {
+			case e, ok := <-m.eventsCh:
+				if !ok {
+					if len(events) > 0 {
+						return batchMsg(events)
+					}
+					return endMsg{}
+				}
+				events = append(events, e)
+			default:
+				return batchMsg(events)
+			}
+		}
+	})
+}
+
+func (m batchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		return m, tea.Quit
+	case spinner.TickMsg:
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+	case batchMsg:
+		for _, e := range msg {
+			r := &m.rows[e.Index]
+			r.status = e.Status
+			r.err = e.Update.Err
+			if e.Update.Description != "" {
+				r.description = e.Update.Description
+			}
+		}
+		return m, m.updateBatch()
+	case endMsg:
+		m.complete = true
+		return m, tea.Quit
+	}
+
+	return m, nil
+}
+
+func (m batchModel) View() string {
+	var s strings.Builder
+	s.WriteString("\n")
+
+	if m.complete {
+		s.WriteString("  ")
+	} else {
+		s.WriteString(m.spinner.View())
+	}
+
+	s.WriteString(fmt.Sprintf("🐙 Mapping %d repositories...\n\n", len(m.rows)))
+	for _, r := range m.rows {
+		switch r.status {
+		case batch.Pending:
+			s.WriteString(fmt.Sprintf("%s %s\n", pendingMark, r.slug))
+		case batch.Running:
+			s.WriteString(fmt.Sprintf("%s%s %s\n", m.spinner.View(), r.slug, helpStyle(r.description)))
+		case batch.Done:
+			s.WriteString(fmt.Sprintf("%s %s %s\n", checkMark, r.slug, helpStyle(r.description)))
+		case batch.Failed:
+			s.WriteString(fmt.Sprintf("%s %s %s\n", errorMark, r.slug, r.err.Error()))
+		}
+	}
+
+	if m.complete {
+		s.WriteString("\nProcess finished!\n\n")
+	} else {
+		s.WriteString(helpStyle("\nPress any key to exit"))
+	}
+
+	return mainStyle.Render(s.String())
+}
+
+// Complete reports whether every repository finished before the program quit.
+func (m batchModel) Complete() bool {
+	return m.complete
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/iamhectorsosa/octomap/internal/progress"
 	"github.com/iamhectorsosa/octomap/pkg/processor"
 )
 
@@ -18,13 +19,25 @@ var (
 	errorMark = lipgloss.NewStyle().Foreground(lipgloss.Color("160")).SetString("x")
 )
 
+const (
+	maxUpdates     = 6
+	updatesBuffer  = 1024
+	renderInterval = 50 * time.Millisecond
+)
+
 type model struct {
 	err       error
 	config    *processor.Config
 	updatesCh chan processor.Update
 	updates   []processor.Update
+	stats     *processor.Stats
+	result    *processedMsg
+	viewer    viewerModel
 	spinner   spinner.Model
+	width     int
+	height    int
 	complete  bool
+	viewing   bool
 }
 
 func New(config *processor.Config) model {
@@ -36,39 +49,89 @@ func New(config *processor.Config) model {
 		config:    config,
 		spinner:   sp,
 		updates:   []processor.Update{},
-		updatesCh: make(chan processor.Update),
+		updatesCh: make(chan processor.Update, updatesBuffer),
 	}
 }
 
 func (m model) Init() tea.Cmd {
-	processor := processor.New(m.config, m.updatesCh)
-	go processor.Process(time.Millisecond)
-	return tea.Batch(m.spinner.Tick, m.updateProcess())
+	return tea.Batch(m.spinner.Tick, m.updateProcess(), m.process())
 }
 
 type (
-	errMsg    struct{ err error }
-	updateMsg processor.Update
-	endMsg    struct{}
+	errMsg       struct{ err error }
+	updateMsg    []processor.Update
+	endMsg       struct{}
+	processedMsg struct {
+		data       processor.RepositoryData
+		reportPath string
+	}
 )
 
+// process runs the processor, reporting its updates on the updates channel
+// and its result once done. Errors are reported as updates.
+func (m model) process() tea.Cmd {
+	return func() tea.Msg {
+		p := processor.New(m.config, m.updatesCh)
+		data, err := p.Process()
+		if err != nil {
+			return nil
+		}
+		return processedMsg{data: data, reportPath: p.ReportPath()}
+	}
+}
+
+// view switches to the result viewer once processing completed and the
+// result is known, or quits when there is nothing to browse.
+func (m model) view() (tea.Model, tea.Cmd) {
+	if !m.complete || m.result == nil {
+		return m, nil
+	}
+	if len(m.result.data) == 0 || m.width == 0 {
+		return m, tea.Quit
+	}
+	m.viewing = true
+	m.viewer = newViewer(m.result.data, m.result.reportPath, m.width, m.height)
+	return m, tea.EnterAltScreen
+}
+
 func (e errMsg) Error() string { return e.err.Error() }
 
+// updateProcess drains every update received since the previous render tick,
+// so the processor never waits on the UI to draw a frame.
 func (m model) updateProcess() tea.Cmd {
-	return func() tea.Msg {
-		update, ok := <-m.updatesCh
-		if !ok {
-			return endMsg{}
+	return tea.Tick(renderInterval, func(time.Time) tea.Msg {
+		var updates []processor.Update
+		for {
+			select {
+			case update, ok := <-m.updatesCh:
+				if !ok {
+					if len(updates) > 0 {
+						return updateMsg(updates)
+					}
+					return endMsg{}
+				}
+				if update.Err != nil {
+					return errMsg{update.Err}
+				}
+				updates = append(updates, update)
+			default:
+				return updateMsg(updates)
+			}
 		}
-		if update.Err != nil {
-			return errMsg{update.Err}
-		}
-		return updateMsg(update)
-	}
+	})
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if m.viewing {
+		var cmd tea.Cmd
+		m.viewer, cmd = m.viewer.Update(msg)
+		return m, cmd
+	}
+
 	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		return m, nil
 	case tea.KeyMsg:
 		return m, tea.Quit
 	case spinner.TickMsg:
@@ -79,20 +142,32 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.err = msg
 		return m, tea.Quit
 	case updateMsg:
-		m.updates = append(m.updates, processor.Update(msg))
-		if len(m.updates) > 6 {
-			m.updates = m.updates[1:]
+		for _, update := range msg {
+			if update.Stats != nil {
+				m.stats = update.Stats
+			}
+		}
+		m.updates = append(m.updates, msg...)
+		if len(m.updates) > maxUpdates {
+			m.updates = m.updates[len(m.updates)-maxUpdates:]
 		}
 		return m, m.updateProcess()
 	case endMsg:
 		m.complete = true
-		return m, tea.Quit
+		return m.view()
+	case processedMsg:
+		m.result = &msg
+		return m.view()
 	}
 
 	return m, nil
 }
 
 func (m model) View() string {
+	if m.viewing {
+		return m.viewer.View()
+	}
+
 	var s strings.Builder
 	s.WriteString("\n")
 
@@ -111,6 +186,16 @@ func (m model) View() string {
 		s.WriteString(fmt.Sprintf("%s %s\n", errorMark, m.err.Error()))
 	}
 
+	if m.complete && m.stats != nil && len(m.stats.Languages) > 0 {
+		s.WriteString("\n")
+		for _, l := range m.stats.Languages {
+			s.WriteString(helpStyle(progress.FormatLanguage(l)) + "\n")
+		}
+		for _, line := range progress.FormatSkipped(m.stats.Skipped) {
+			s.WriteString(helpStyle(line) + "\n")
+		}
+	}
+
 	if m.complete || m.err != nil {
 		s.WriteString("\nProcess finished!\n\n")
 	} else {
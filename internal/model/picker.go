@@ -0,0 +1,393 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/iamhectorsosa/octomap/pkg/processor"
+)
+
+var (
+	cursorStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
+	selectedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("42"))
+)
+
+const defaultPickerHeight = 20
+
+// pickerNode is a file or directory of the picker tree. Only files hold a
+// selection, directories derive theirs from their descendants.
+type pickerNode struct {
+	parent   *pickerNode
+	name     string
+	path     string
+	children []*pickerNode
+	size     int64
+	tokens   int
+	dir      bool
+	expanded bool
+	selected bool
+}
+
+// pickerRow is a visible node at a depth of the tree.
+type pickerRow struct {
+	node  *pickerNode
+	depth int
+}
+
+type pickerModel struct {
+	root      *pickerNode
+	files     []*pickerNode
+	rows      []pickerRow
+	search    textinput.Model
+	cursor    int
+	offset    int
+	height    int
+	confirmed bool
+}
+
+// NewPicker returns a tree view to select which of the inspected files are
+// mapped. Every file starts selected and every directory collapsed.
+func NewPicker(files []processor.InspectedFile) pickerModel {
+	search := textinput.New()
+	search.Prompt = "/"
+	search.Placeholder = "search"
+
+	m := pickerModel{
+		root:   &pickerNode{dir: true, expanded: true},
+		search: search,
+		height: defaultPickerHeight,
+	}
+	for _, f := range files {
+		m.files = append(m.files, m.root.add(f))
+	}
+	m.root.sort()
+	m.refresh()
+	return m
+}
+
+// add inserts a file below the node, creating the directories on its path.
+func (n *pickerNode) add(f processor.InspectedFile) *pickerNode {
+	current := n
+	parts := strings.Split(f.Path, "/")
+	for i, part := range parts[:len(parts)-1] {
+		var next *pickerNode
+		for _, child := range current.children {
+			if child.dir && child.name == part {
+				next = child
+				break
+			}
+		}
+		if next == nil {
+			next = &pickerNode{
+				parent: current,
+				name:   part,
+				path:   strings.Join(parts[:i+1], "/"),
+				dir:    true,
+			}
+			current.children = append(current.children, next)
+		}
+		current = next
+	}
+
+	file := &pickerNode{
+		parent:   current,
+		name:     parts[len(parts)-1],
+		path:     f.Path,
+		size:     f.Size,
+		tokens:   f.Tokens,
+		selected: true,
+	}
+	current.children = append(current.children, file)
+	return file
+}
+
+// sort orders directories before files, each by name.
+func (n *pickerNode) sort() {
+	sort.Slice(n.children, func(i, j int) bool {
+		a, b := n.children[i], n.children[j]
+		if a.dir != b.dir {
+			return a.dir
+		}
+		return a.name < b.name
+	})
+	for _, child := range n.children {
+		child.sort()
+	}
+}
+
+// walk calls fn for every file below the node.
+func (n *pickerNode) walk(fn func(f *pickerNode)) {
+	if !n.dir {
+		fn(n)
+		return
+	}
+	for _, child := range n.children {
+		child.walk(fn)
+	}
+}
+
+// state counts the selected files below the node.
+func (n *pickerNode) state() (selected, total int) {
+	n.walk(func(f *pickerNode) {
+		total++
+		if f.selected {
+			selected++
+		}
+	})
+	return selected, total
+}
+
+// toggle selects every file below the node matching the query, or
+// deselects them all when they already are.
+func (n *pickerNode) toggle(query string) {
+	var files []*pickerNode
+	allSelected := true
+	n.walk(func(f *pickerNode) {
+		if query == "" || fuzzyMatch(query, f.path) {
+			files = append(files, f)
+			allSelected = allSelected && f.selected
+		}
+	})
+	for _, f := range files {
+		f.selected = !allSelected
+	}
+}
+
+// refresh rebuilds the visible rows. While searching, every file matching
+// the query is shown with its directories.
+func (m *pickerModel) refresh() {
+	query := m.search.Value()
+	m.rows = m.rows[:0]
+
+	var visit func(n *pickerNode, depth int)
+	visit = func(n *pickerNode, depth int) {
+		for _, child := range n.children {
+			if query != "" && !m.matches(child, query) {
+				continue
+			}
+			m.rows = append(m.rows, pickerRow{node: child, depth: depth})
+			if child.dir && (child.expanded || query != "") {
+				visit(child, depth+1)
+			}
+		}
+	}
+	visit(m.root, 0)
+
+	m.cursor = min(m.cursor, max(len(m.rows)-1, 0))
+	m.scroll()
+}
+
+// matches reports whether the node or any file below it matches the query.
+func (m *pickerModel) matches(n *pickerNode, query string) bool {
+	found := false
+	n.walk(func(f *pickerNode) {
+		found = found || fuzzyMatch(query, f.path)
+	})
+	return found
+}
+
+// fuzzyMatch reports whether the characters of pattern appear in s in order,
+// ignoring case.
+func fuzzyMatch(pattern, s string) bool {
+	s = strings.ToLower(s)
+	for _, r := range strings.ToLower(pattern) {
+		i := strings.IndexRune(s, r)
+		if i < 0 {
+			return false
+		}
+		s = s[i+len(string(r)):]
+	}
+	return true
+}
+
+// scroll keeps the cursor within the visible window.
+func (m *pickerModel) scroll() {
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+m.height {
+		m.offset = m.cursor - m.height + 1
+	}
+}
+
+// Confirmed reports whether the selection was confirmed rather than
+// cancelled.
+func (m pickerModel) Confirmed() bool {
+	return m.confirmed
+}
+
+// Selected returns the paths of the selected files, in tree order.
+func (m pickerModel) Selected() []string {
+	var paths []string
+	m.root.walk(func(f *pickerNode) {
+		if f.selected {
+			paths = append(paths, f.path)
+		}
+	})
+	return paths
+}
+
+func (m pickerModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m pickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		// Leave room for the title, search and help lines
+		m.height = max(msg.Height-8, 1)
+		m.scroll()
+		return m, nil
+	case tea.KeyMsg:
+		if m.search.Focused() {
+			return m.updateSearch(msg)
+		}
+		return m.updateTree(msg)
+	}
+	return m, nil
+}
+
+func (m pickerModel) updateSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc":
+		m.search.SetValue("")
+		m.search.Blur()
+		m.refresh()
+		return m, nil
+	case "enter":
+		m.search.Blur()
+		return m, nil
+	case "up", "down":
+		m.search.Blur()
+		return m.updateTree(msg)
+	}
+
+	var cmd tea.Cmd
+	m.search, cmd = m.search.Update(msg)
+	m.cursor = 0
+	m.refresh()
+	return m, cmd
+}
+
+func (m pickerModel) updateTree(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var current *pickerNode
+	if len(m.rows) > 0 {
+		current = m.rows[m.cursor].node
+	}
+
+	switch msg.String() {
+	case "esc":
+		if m.search.Value() == "" {
+			return m, tea.Quit
+		}
+		m.search.SetValue("")
+	case "ctrl+c", "q":
+		return m, tea.Quit
+	case "enter":
+		if len(m.Selected()) > 0 {
+			m.confirmed = true
+			return m, tea.Quit
+		}
+	case "/":
+		m.search.Focus()
+		return m, textinput.Blink
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < len(m.rows)-1 {
+			m.cursor++
+		}
+	case "right", "l":
+		if current != nil && current.dir {
+			current.expanded = true
+		}
+	case "left", "h":
+		if current != nil && current.dir && current.expanded {
+			current.expanded = false
+		} else if current != nil && current.parent != m.root {
+			for i, row := range m.rows {
+				if row.node == current.parent {
+					m.cursor = i
+				}
+			}
+		}
+	case " ":
+		if current != nil {
+			current.toggle(m.search.Value())
+		}
+	case "a":
+		m.root.toggle(m.search.Value())
+	}
+
+	m.refresh()
+	return m, nil
+}
+
+func (m pickerModel) View() string {
+	var s strings.Builder
+	s.WriteString("\n🐙 Select files to map\n\n")
+
+	end := min(m.offset+m.height, len(m.rows))
+	for i := m.offset; i < end; i++ {
+		s.WriteString(m.viewRow(i) + "\n")
+	}
+	if len(m.rows) == 0 {
+		s.WriteString(helpStyle("no files match") + "\n")
+	}
+
+	var files, tokens int
+	m.root.walk(func(f *pickerNode) {
+		if f.selected {
+			files++
+			tokens += f.tokens
+		}
+	})
+	s.WriteString(fmt.Sprintf("\n%d of %d files selected, ~%d tokens\n", files, len(m.files), tokens))
+
+	if m.search.Focused() || m.search.Value() != "" {
+		s.WriteString(m.search.View() + "\n")
+	}
+	s.WriteString(helpStyle("space toggle • a toggle all • ←/→ collapse/expand • / search • enter map • q quit"))
+
+	return mainStyle.Render(s.String())
+}
+
+func (m pickerModel) viewRow(i int) string {
+	row := m.rows[i]
+	n := row.node
+
+	cursor := "  "
+	if i == m.cursor {
+		cursor = cursorStyle.Render("> ")
+	}
+
+	box := "[ ]"
+	selected, total := n.state()
+	switch {
+	case selected == total:
+		box = selectedStyle.Render("[x]")
+	case selected > 0:
+		box = selectedStyle.Render("[-]")
+	}
+
+	name := n.name
+	if n.dir {
+		arrow := "▸ "
+		if n.expanded || m.search.Value() != "" {
+			arrow = "▾ "
+		}
+		name = arrow + name + "/"
+	} else {
+		name = "  " + name + helpStyle(fmt.Sprintf(" %d tokens", n.tokens))
+	}
+
+	return cursor + strings.Repeat("  ", row.depth) + box + " " + name
+}
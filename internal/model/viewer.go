@@ -0,0 +1,206 @@
+package model
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"sort"
+	"time"
+
+	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/iamhectorsosa/octomap/pkg/processor"
+)
+
+var (
+	paneStyle        = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("241"))
+	focusedPaneStyle = paneStyle.BorderForeground(lipgloss.Color("205"))
+)
+
+const statusLifetime = 3 * time.Second
+
+var (
+	copyKey   = key.NewBinding(key.WithKeys("c"), key.WithHelp("c", "copy report path"))
+	openKey   = key.NewBinding(key.WithKeys("o"), key.WithHelp("o", "open report"))
+	switchKey = key.NewBinding(key.WithKeys("tab"), key.WithHelp("tab", "switch pane"))
+)
+
+// mappedFile is a list item for a file of the report.
+type mappedFile struct {
+	path    string
+	content string
+	size    int64
+	tokens  int
+	tree    bool
+}
+
+func (f mappedFile) Title() string       { return f.path }
+func (f mappedFile) FilterValue() string { return f.path }
+func (f mappedFile) Description() string {
+	return fmt.Sprintf("%d bytes, ~%d tokens", f.size, f.tokens)
+}
+
+// mappedFiles flattens repository data into files sorted by path. Tree-only
+// data maps files to their size instead of their content.
+func mappedFiles(data processor.RepositoryData) []mappedFile {
+	var files []mappedFile
+
+	var visit func(node map[string]interface{}, prefix string)
+	visit = func(node map[string]interface{}, prefix string) {
+		for name, v := range node {
+			path := prefix + name
+			switch v := v.(type) {
+			case map[string]interface{}:
+				visit(v, path+"/")
+			case string:
+				files = append(files, mappedFile{
+					path:    path,
+					content: v,
+					size:    int64(len(v)),
+					tokens:  processor.EstimateTokens(v),
+				})
+			case int64:
+				files = append(files, mappedFile{path: path, size: v, tokens: processor.EstimateSizeTokens(v), tree: true})
+			}
+		}
+	}
+	visit(data, "")
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].path < files[j].path
+	})
+	return files
+}
+
+// viewerModel browses the mapped files after processing: a list of files
+// next to a preview of the selected one.
+type viewerModel struct {
+	list         list.Model
+	preview      viewport.Model
+	reportPath   string
+	previewPath  string
+	width        int
+	height       int
+	previewFocus bool
+}
+
+func newViewer(data processor.RepositoryData, reportPath string, width, height int) viewerModel {
+	files := mappedFiles(data)
+	items := make([]list.Item, len(files))
+	for i, f := range files {
+		items[i] = f
+	}
+
+	l := list.New(items, list.NewDefaultDelegate(), 0, 0)
+	l.Title = fmt.Sprintf("🐙 %d mapped files", len(files))
+	l.StatusMessageLifetime = statusLifetime
+	l.AdditionalShortHelpKeys = func() []key.Binding {
+		return []key.Binding{switchKey, copyKey, openKey}
+	}
+	l.AdditionalFullHelpKeys = l.AdditionalShortHelpKeys
+
+	m := viewerModel{
+		list:       l,
+		preview:    viewport.New(0, 0),
+		reportPath: reportPath,
+	}
+	m.resize(width, height)
+	return m
+}
+
+// resize splits the screen between the list and the preview, accounting for
+// the pane borders.
+func (m *viewerModel) resize(width, height int) {
+	m.width, m.height = width, height
+	listWidth := width * 2 / 5
+	m.list.SetSize(max(listWidth-2, 1), max(height-2, 1))
+	m.preview.Width = max(width-listWidth-2, 1)
+	m.preview.Height = max(height-2, 1)
+	m.previewPath = ""
+	m.updatePreview()
+}
+
+// updatePreview shows the selected file, resetting the scroll position when
+// the selection changes.
+func (m *viewerModel) updatePreview() {
+	f, ok := m.list.SelectedItem().(mappedFile)
+	if !ok || f.path == m.previewPath {
+		return
+	}
+	m.previewPath = f.path
+
+	content := f.content
+	if f.tree {
+		content = helpStyle(fmt.Sprintf("tree-only report, %d bytes", f.size))
+	}
+	m.preview.SetContent(lipgloss.NewStyle().Width(m.preview.Width).Render(content))
+	m.preview.GotoTop()
+}
+
+func (m viewerModel) Update(msg tea.Msg) (viewerModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.resize(msg.Width, msg.Height)
+		return m, nil
+	case tea.KeyMsg:
+		if m.list.FilterState() == list.Filtering {
+			break
+		}
+		switch {
+		case key.Matches(msg, switchKey):
+			m.previewFocus = !m.previewFocus
+			return m, nil
+		case key.Matches(msg, copyKey):
+			if err := clipboard.WriteAll(m.reportPath); err != nil {
+				return m, m.list.NewStatusMessage(errorMark.String() + " " + err.Error())
+			}
+			return m, m.list.NewStatusMessage(checkMark.String() + " copied " + m.reportPath)
+		case key.Matches(msg, openKey):
+			if err := openFile(m.reportPath); err != nil {
+				return m, m.list.NewStatusMessage(errorMark.String() + " " + err.Error())
+			}
+			return m, m.list.NewStatusMessage(checkMark.String() + " opened " + m.reportPath)
+		}
+		if m.previewFocus && msg.String() != "q" && msg.String() != "ctrl+c" {
+			var cmd tea.Cmd
+			m.preview, cmd = m.preview.Update(msg)
+			return m, cmd
+		}
+	}
+
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+	m.updatePreview()
+	return m, cmd
+}
+
+func (m viewerModel) View() string {
+	listPane, previewPane := focusedPaneStyle, paneStyle
+	if m.previewFocus {
+		listPane, previewPane = paneStyle, focusedPaneStyle
+	}
+
+	return lipgloss.JoinHorizontal(
+		lipgloss.Top,
+		listPane.Render(m.list.View()),
+		previewPane.Render(m.preview.View()),
+	)
+}
+
+// openFile opens a file with the default application of the platform.
+func openFile(path string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", path)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", path)
+	default:
+		cmd = exec.Command("xdg-open", path)
+	}
+	return cmd.Start()
+}
@@ -0,0 +1,127 @@
+package progress
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/iamhectorsosa/octomap/internal/batch"
+	"github.com/iamhectorsosa/octomap/pkg/processor"
+)
+
+const (
+	Auto  = "auto"
+	TUI   = "tui"
+	Plain = "plain"
+	JSON  = "json"
+	None  = "none"
+)
+
+var Modes = []string{Auto, TUI, Plain, JSON, None}
+
+type event struct {
+	Stats       *processor.Stats `json:"stats,omitempty"`
+	Time        string           `json:"time"`
+	Level       string           `json:"level"`
+	Repo        string           `json:"repo,omitempty"`
+	Status      string           `json:"status,omitempty"`
+	Description string           `json:"description,omitempty"`
+	Error       string           `json:"error,omitempty"`
+}
+
+var statuses = map[batch.Status]string{
+	batch.Pending: "pending",
+	batch.Running: "running",
+	batch.Done:    "done",
+	batch.Failed:  "failed",
+}
+
+// WritePlain writes one line per update until the channel is closed.
+func WritePlain(w io.Writer, ch <-chan processor.Update) {
+	for update := range ch {
+		writePlain(w, "", update)
+	}
+}
+
+// WriteJSON writes one JSON object per update until the channel is closed.
+func WriteJSON(w io.Writer, ch <-chan processor.Update) {
+	encoder := json.NewEncoder(w)
+	for update := range ch {
+		encoder.Encode(newEvent(update))
+	}
+}
+
+// WriteBatchPlain writes one line per batch event, prefixed with the
+// repository, until the channel is closed.
+func WriteBatchPlain(w io.Writer, ch <-chan batch.Event) {
+	for e := range ch {
+		if e.Update.Err == nil && e.Update.Description == "" {
+			continue
+		}
+		writePlain(w, fmt.Sprintf("[%s] ", e.Slug), e.Update)
+	}
+}
+
+// WriteBatchJSON writes one JSON object per batch event until the channel is
+// closed.
+func WriteBatchJSON(w io.Writer, ch <-chan batch.Event) {
+	encoder := json.NewEncoder(w)
+	for e := range ch {
+		ev := newEvent(e.Update)
+		ev.Repo = e.Slug
+		ev.Status = statuses[e.Status]
+		encoder.Encode(ev)
+	}
+}
+
+func writePlain(w io.Writer, prefix string, update processor.Update) {
+	if update.Err != nil {
+		fmt.Fprintf(w, "%serror: %v\n", prefix, update.Err)
+		return
+	}
+	fmt.Fprintf(w, "%s%s\n", prefix, update.Description)
+	if update.Stats != nil {
+		for _, l := range update.Stats.Languages {
+			fmt.Fprintf(w, "%s  %s\n", prefix, FormatLanguage(l))
+		}
+		for _, line := range FormatSkipped(update.Stats.Skipped) {
+			fmt.Fprintf(w, "%s  %s\n", prefix, line)
+		}
+	}
+}
+
+// FormatLanguage renders one line of the per-language statistics.
+func FormatLanguage(l processor.LanguageStats) string {
+	return fmt.Sprintf("%-16s %6d files %8d lines %10d bytes %9d tokens", l.Language, l.Files, l.Lines, l.Bytes, l.Tokens)
+}
+
+// FormatSkipped renders one line per skip reason, ordered by reason.
+func FormatSkipped(counts map[string]int) []string {
+	reasons := make([]string, 0, len(counts))
+	for reason := range counts {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+
+	lines := make([]string, len(reasons))
+	for i, reason := range reasons {
+		lines[i] = fmt.Sprintf("skipped %-15s %6d files", reason, counts[reason])
+	}
+	return lines
+}
+
+func newEvent(update processor.Update) event {
+	e := event{
+		Time:        time.Now().UTC().Format(time.RFC3339Nano),
+		Level:       "info",
+		Description: update.Description,
+		Stats:       update.Stats,
+	}
+	if update.Err != nil {
+		e.Level = "error"
+		e.Error = update.Err.Error()
+	}
+	return e
+}
@@ -0,0 +1,98 @@
+package progress
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/iamhectorsosa/octomap/internal/batch"
+	"github.com/iamhectorsosa/octomap/pkg/processor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sendUpdates(updates ...processor.Update) <-chan processor.Update {
+	ch := make(chan processor.Update, len(updates))
+	for _, update := range updates {
+		ch <- update
+	}
+	close(ch)
+	return ch
+}
+
+func TestWritePlain(t *testing.T) {
+	var buf bytes.Buffer
+	WritePlain(&buf, sendUpdates(
+		processor.Update{Description: "downloading: url"},
+		processor.Update{Err: errors.New("unexpected status code: 404")},
+	))
+
+	assert.Equal(t, "downloading: url\nerror: unexpected status code: 404\n", buf.String())
+}
+
+func TestWritePlainStats(t *testing.T) {
+	var buf bytes.Buffer
+	WritePlain(&buf, sendUpdates(processor.Update{
+		Description: "prepared: 1 out of 4 files for report",
+		Stats: &processor.Stats{
+			Languages: []processor.LanguageStats{{Language: "Go", Files: 1, Bytes: 13, Lines: 1, Tokens: 4}},
+			Skipped:   map[string]int{processor.SkipNotIncluded: 2, processor.SkipExcluded: 1},
+		},
+	}))
+
+	assert.Equal(t, "prepared: 1 out of 4 files for report\n"+
+		"  "+FormatLanguage(processor.LanguageStats{Language: "Go", Files: 1, Bytes: 13, Lines: 1, Tokens: 4})+"\n"+
+		"  skipped excluded             1 files\n"+
+		"  skipped not-included         2 files\n", buf.String())
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	WriteJSON(&buf, sendUpdates(
+		processor.Update{Description: "mapped: main.go"},
+		processor.Update{Err: errors.New("request error")},
+	))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+
+	var first, second event
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &first))
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &second))
+
+	assert.Equal(t, "info", first.Level)
+	assert.Equal(t, "mapped: main.go", first.Description)
+	assert.NotEmpty(t, first.Time)
+	assert.Equal(t, "error", second.Level)
+	assert.Equal(t, "request error", second.Error)
+}
+
+func TestWriteBatchPlain(t *testing.T) {
+	ch := make(chan batch.Event, 3)
+	ch <- batch.Event{Slug: "user/a", Status: batch.Running}
+	ch <- batch.Event{Slug: "user/a", Status: batch.Running, Update: processor.Update{Description: "mapped: main.go"}}
+	ch <- batch.Event{Slug: "user/b", Status: batch.Failed, Update: processor.Update{Err: errors.New("request error")}}
+	close(ch)
+
+	var buf bytes.Buffer
+	WriteBatchPlain(&buf, ch)
+
+	assert.Equal(t, "[user/a] mapped: main.go\n[user/b] error: request error\n", buf.String())
+}
+
+func TestWriteBatchJSON(t *testing.T) {
+	ch := make(chan batch.Event, 1)
+	ch <- batch.Event{Slug: "user/a", Status: batch.Done, Update: processor.Update{Description: "generated report: a.json"}}
+	close(ch)
+
+	var buf bytes.Buffer
+	WriteBatchJSON(&buf, ch)
+
+	var e event
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &e))
+	assert.Equal(t, "user/a", e.Repo)
+	assert.Equal(t, "done", e.Status)
+	assert.Equal(t, "generated report: a.json", e.Description)
+}
@@ -7,15 +7,31 @@ import (
 	"io"
 )
 
+// Link types of archive entries that point to another path.
+const (
+	LinkSymbolic = "symlink"
+	LinkHard     = "hardlink"
+)
+
 type TarGzReader struct {
 	gzipReader *gzip.Reader
 	tarReader  *tar.Reader
 }
 
+// ArchiveHeader describes an archive entry. Commit is the commit ID git
+// archive records in the pax global header, set on that entry only. LinkType
+// is set for symbolic and hard links, with LinkTarget as recorded in the
+// archive: relative to the link for symbolic links and an entry name for hard
+// links. Entries that are neither directories, files nor links, such as
+// devices and pax global headers, have none of IsDir, IsFile and LinkType.
 type ArchiveHeader struct {
-	Name   string
-	IsDir  bool
-	IsFile bool
+	Name       string
+	Commit     string
+	LinkType   string
+	LinkTarget string
+	Size       int64
+	IsDir      bool
+	IsFile     bool
 }
 
 func NewTarGzReader(r io.Reader) (*TarGzReader, error) {
@@ -38,11 +54,21 @@ func (r *TarGzReader) ReadNext() (*ArchiveHeader, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &ArchiveHeader{
+	archiveHeader := &ArchiveHeader{
 		Name:   header.Name,
+		Size:   header.Size,
 		IsDir:  header.Typeflag == tar.TypeDir,
 		IsFile: header.Typeflag == tar.TypeReg,
-	}, nil
+	}
+	switch header.Typeflag {
+	case tar.TypeXGlobalHeader:
+		archiveHeader.Commit = header.PAXRecords["comment"]
+	case tar.TypeSymlink:
+		archiveHeader.LinkType, archiveHeader.LinkTarget = LinkSymbolic, header.Linkname
+	case tar.TypeLink:
+		archiveHeader.LinkType, archiveHeader.LinkTarget = LinkHard, header.Linkname
+	}
+	return archiveHeader, nil
 }
 
 func (r *TarGzReader) ReadContent() (string, error) {
@@ -0,0 +1,59 @@
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadNext(t *testing.T) {
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+
+	headers := []*tar.Header{
+		{Typeflag: tar.TypeXGlobalHeader, Name: "pax_global_header", PAXRecords: map[string]string{"comment": "abc123"}},
+		{Typeflag: tar.TypeDir, Name: "repo-main/", Mode: 0755},
+		{Typeflag: tar.TypeReg, Name: "repo-main/main.go", Mode: 0644, Size: 13},
+		{Typeflag: tar.TypeSymlink, Name: "repo-main/link.go", Linkname: "main.go", Mode: 0777},
+		{Typeflag: tar.TypeLink, Name: "repo-main/copy.go", Linkname: "repo-main/main.go", Mode: 0644},
+		{Typeflag: tar.TypeFifo, Name: "repo-main/pipe", Mode: 0644},
+	}
+	for _, hdr := range headers {
+		require.NoError(t, tw.WriteHeader(hdr))
+		if hdr.Size > 0 {
+			_, err := tw.Write([]byte("package main\n"))
+			require.NoError(t, err)
+		}
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gw.Close())
+
+	r, err := NewTarGzReader(&buf)
+	require.NoError(t, err)
+	defer r.Close()
+
+	var got []ArchiveHeader
+	for {
+		hdr, err := r.ReadNext()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		got = append(got, *hdr)
+	}
+
+	assert.Equal(t, []ArchiveHeader{
+		{Name: "pax_global_header", Commit: "abc123"},
+		{Name: "repo-main/", IsDir: true},
+		{Name: "repo-main/main.go", Size: 13, IsFile: true},
+		{Name: "repo-main/link.go", LinkType: LinkSymbolic, LinkTarget: "main.go"},
+		{Name: "repo-main/copy.go", LinkType: LinkHard, LinkTarget: "repo-main/main.go"},
+		{Name: "repo-main/pipe"},
+	}, got)
+}
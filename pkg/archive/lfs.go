@@ -0,0 +1,68 @@
+package archive
+
+import (
+	"strconv"
+	"strings"
+)
+
+const (
+	// LFSPointerMaxSize is the largest size of a Git LFS pointer file.
+	LFSPointerMaxSize = 1024
+
+	lfsVersion   = "version https://git-lfs.github.com/spec/v1"
+	lfsOidPrefix = "sha256:"
+)
+
+// LFSPointer is a Git LFS pointer file, committed in place of an object
+// stored on the LFS server. Oid is the SHA-256 of the object.
+type LFSPointer struct {
+	Oid  string
+	Size int64
+}
+
+// ParseLFSPointer parses the content of a file as a Git LFS pointer,
+// reporting whether it is one.
+func ParseLFSPointer(content string) (*LFSPointer, bool) {
+	if len(content) > LFSPointerMaxSize || !strings.HasPrefix(content, lfsVersion+"\n") {
+		return nil, false
+	}
+
+	pointer := &LFSPointer{Size: -1}
+	for _, line := range strings.Split(strings.TrimSuffix(content, "\n"), "\n")[1:] {
+		key, value, ok := strings.Cut(line, " ")
+		if !ok {
+			return nil, false
+		}
+		switch key {
+		case "oid":
+			oid, ok := strings.CutPrefix(value, lfsOidPrefix)
+			if !ok || !isSHA256(oid) {
+				return nil, false
+			}
+			pointer.Oid = oid
+		case "size":
+			size, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || size < 0 {
+				return nil, false
+			}
+			pointer.Size = size
+		}
+	}
+
+	if pointer.Oid == "" || pointer.Size < 0 {
+		return nil, false
+	}
+	return pointer, true
+}
+
+func isSHA256(s string) bool {
+	if len(s) != 64 {
+		return false
+	}
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,53 @@
+package archive
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testOid = "4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393"
+
+func TestParseLFSPointer(t *testing.T) {
+	tests := []struct {
+		want    *LFSPointer
+		name    string
+		content string
+	}{
+		{
+			name:    "pointer",
+			content: "version https://git-lfs.github.com/spec/v1\noid sha256:" + testOid + "\nsize 12345\n",
+			want:    &LFSPointer{Oid: testOid, Size: 12345},
+		},
+		{
+			name:    "pointer with extensions",
+			content: "version https://git-lfs.github.com/spec/v1\next-0-foo sha256:" + testOid + "\noid sha256:" + testOid + "\nsize 0\n",
+			want:    &LFSPointer{Oid: testOid, Size: 0},
+		},
+		{
+			name:    "regular file",
+			content: "package main\n",
+		},
+		{
+			name:    "missing size",
+			content: "version https://git-lfs.github.com/spec/v1\noid sha256:" + testOid + "\n",
+		},
+		{
+			name:    "invalid oid",
+			content: "version https://git-lfs.github.com/spec/v1\noid sha256:1234\nsize 1\n",
+		},
+		{
+			name:    "too large",
+			content: "version https://git-lfs.github.com/spec/v1\noid sha256:" + testOid + "\nsize 1\n" + strings.Repeat("x", LFSPointerMaxSize),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseLFSPointer(tt.content)
+			assert.Equal(t, tt.want != nil, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,93 @@
+// Package compress writes compressed reports and reads reports whatever
+// their compression.
+package compress
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	None = "none"
+	Gzip = "gzip"
+	Zstd = "zstd"
+
+	invalidMethod = "invalid compression, must be one of %s, received %q\n"
+)
+
+var Methods = []string{None, Gzip, Zstd}
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// Ext returns the file extension of a compression method, empty for none.
+func Ext(method string) string {
+	switch method {
+	case Gzip:
+		return ".gz"
+	case Zstd:
+		return ".zst"
+	}
+	return ""
+}
+
+// HasExt reports whether a file name ends with the extension of any
+// compression method.
+func HasExt(name string) bool {
+	for _, method := range Methods {
+		if ext := Ext(method); ext != "" && strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// NewWriter returns a writer compressing to w. Closing it flushes the
+// compressed stream but does not close w.
+func NewWriter(w io.Writer, method string) (io.WriteCloser, error) {
+	switch method {
+	case None, "":
+		return nopCloser{w}, nil
+	case Gzip:
+		return gzip.NewWriter(w), nil
+	case Zstd:
+		return zstd.NewWriter(w)
+	}
+	return nil, fmt.Errorf(invalidMethod, strings.Join(Methods, "|"), method)
+}
+
+// NewReader returns a reader decompressing r when it starts with a gzip or
+// zstd header and reading it as is otherwise.
+func NewReader(r io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+	header, err := br.Peek(len(zstdMagic))
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	switch {
+	case bytes.HasPrefix(header, gzipMagic):
+		return gzip.NewReader(br)
+	case bytes.HasPrefix(header, zstdMagic):
+		decoder, err := zstd.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		return decoder.IOReadCloser(), nil
+	}
+	return io.NopCloser(br), nil
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
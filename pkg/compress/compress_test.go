@@ -0,0 +1,55 @@
+package compress
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoundTrip(t *testing.T) {
+	content := `{"main.go": "package main\n"}`
+
+	for _, method := range Methods {
+		t.Run(method, func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := NewWriter(&buf, method)
+			require.NoError(t, err)
+			_, err = io.WriteString(w, content)
+			require.NoError(t, err)
+			require.NoError(t, w.Close())
+
+			if method != None {
+				assert.NotEqual(t, content, buf.String())
+			}
+
+			r, err := NewReader(&buf)
+			require.NoError(t, err)
+			defer r.Close()
+			got, err := io.ReadAll(r)
+			require.NoError(t, err)
+			assert.Equal(t, content, string(got))
+		})
+	}
+}
+
+func TestNewReaderShortInput(t *testing.T) {
+	r, err := NewReader(bytes.NewReader([]byte("{}")))
+	require.NoError(t, err)
+	got, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "{}", string(got))
+}
+
+func TestExt(t *testing.T) {
+	assert.Equal(t, "", Ext(None))
+	assert.Equal(t, ".gz", Ext(Gzip))
+	assert.Equal(t, ".zst", Ext(Zstd))
+	assert.True(t, HasExt("report.json.zst"))
+	assert.False(t, HasExt("report.json"))
+
+	_, err := NewWriter(io.Discard, "brotli")
+	assert.Error(t, err)
+}
@@ -0,0 +1,159 @@
+package language
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Other is reported for files no detector recognizes.
+const Other = "Other"
+
+type Language struct {
+	Name       string
+	Aliases    []string
+	Extensions []string
+	Filenames  []string
+	// Interpreters matched against the shebang line
+	Interpreters []string
+}
+
+var Languages = []Language{
+	{Name: "Go", Aliases: []string{"go", "golang"}, Extensions: []string{".go"}},
+	{Name: "Go Module", Aliases: []string{"gomod"}, Filenames: []string{"go.mod", "go.sum", "go.work", "go.work.sum"}},
+	{Name: "TypeScript", Aliases: []string{"typescript", "ts"}, Extensions: []string{".ts", ".tsx", ".mts", ".cts"}, Interpreters: []string{"ts-node", "deno", "tsx"}},
+	{Name: "JavaScript", Aliases: []string{"javascript", "js"}, Extensions: []string{".js", ".jsx", ".mjs", ".cjs"}, Interpreters: []string{"node", "nodejs"}},
+	{Name: "Python", Aliases: []string{"python", "py"}, Extensions: []string{".py", ".pyi", ".pyw"}, Filenames: []string{"SConstruct", "SConscript"}, Interpreters: []string{"python", "python2", "python3"}},
+	{Name: "Ruby", Aliases: []string{"ruby", "rb"}, Extensions: []string{".rb", ".rake", ".gemspec"}, Filenames: []string{"Gemfile", "Rakefile", "Vagrantfile"}, Interpreters: []string{"ruby"}},
+	{Name: "Rust", Aliases: []string{"rust", "rs"}, Extensions: []string{".rs"}},
+	{Name: "Java", Aliases: []string{"java"}, Extensions: []string{".java"}},
+	{Name: "Kotlin", Aliases: []string{"kotlin", "kt"}, Extensions: []string{".kt", ".kts"}},
+	{Name: "Scala", Aliases: []string{"scala"}, Extensions: []string{".scala", ".sc"}},
+	{Name: "Swift", Aliases: []string{"swift"}, Extensions: []string{".swift"}},
+	{Name: "C", Aliases: []string{"c"}, Extensions: []string{".c", ".h"}},
+	{Name: "C++", Aliases: []string{"c++", "cpp", "cxx"}, Extensions: []string{".cc", ".cpp", ".cxx", ".hh", ".hpp", ".hxx"}},
+	{Name: "C#", Aliases: []string{"c#", "csharp", "cs"}, Extensions: []string{".cs"}},
+	{Name: "PHP", Aliases: []string{"php"}, Extensions: []string{".php"}, Interpreters: []string{"php"}},
+	{Name: "Perl", Aliases: []string{"perl", "pl"}, Extensions: []string{".pl", ".pm"}, Interpreters: []string{"perl"}},
+	{Name: "Lua", Aliases: []string{"lua"}, Extensions: []string{".lua"}, Interpreters: []string{"lua"}},
+	{Name: "Shell", Aliases: []string{"shell", "sh", "bash", "zsh"}, Extensions: []string{".sh", ".bash", ".zsh"}, Filenames: []string{".bashrc", ".zshrc", ".profile"}, Interpreters: []string{"sh", "bash", "zsh", "dash", "ksh"}},
+	{Name: "HTML", Aliases: []string{"html"}, Extensions: []string{".html", ".htm"}},
+	{Name: "CSS", Aliases: []string{"css"}, Extensions: []string{".css", ".scss", ".sass", ".less"}},
+	{Name: "SQL", Aliases: []string{"sql"}, Extensions: []string{".sql"}},
+	{Name: "Protocol Buffers", Aliases: []string{"protobuf", "proto"}, Extensions: []string{".proto"}},
+	{Name: "Markdown", Aliases: []string{"markdown", "md"}, Extensions: []string{".md", ".markdown"}},
+	{Name: "JSON", Aliases: []string{"json"}, Extensions: []string{".json"}},
+	{Name: "YAML", Aliases: []string{"yaml", "yml"}, Extensions: []string{".yaml", ".yml"}},
+	{Name: "TOML", Aliases: []string{"toml"}, Extensions: []string{".toml"}},
+	{Name: "XML", Aliases: []string{"xml"}, Extensions: []string{".xml"}},
+	{Name: "Dockerfile", Aliases: []string{"dockerfile", "docker"}, Extensions: []string{".dockerfile"}, Filenames: []string{"Dockerfile", "Containerfile"}},
+	{Name: "Makefile", Aliases: []string{"makefile", "make"}, Extensions: []string{".mk", ".mak"}, Filenames: []string{"Makefile", "GNUmakefile", "makefile"}},
+	{Name: "Text", Aliases: []string{"text", "txt"}, Extensions: []string{".txt"}, Filenames: []string{"LICENSE", "COPYING", "AUTHORS"}},
+}
+
+var (
+	byExtension   = make(map[string]string)
+	byFilename    = make(map[string]string)
+	byInterpreter = make(map[string]string)
+	byAlias       = make(map[string]string)
+
+	vimModeline   = regexp.MustCompile(`(?:vi|vim|ex):.*?\b(?:ft|filetype|syntax)=([A-Za-z0-9+#]+)`)
+	emacsModeline = regexp.MustCompile(`-\*-.*?(?:mode:\s*)?([A-Za-z0-9+#]+)\s*(?:;.*)?-\*-`)
+)
+
+func init() {
+	for _, l := range Languages {
+		byAlias[strings.ToLower(l.Name)] = l.Name
+		for _, alias := range l.Aliases {
+			byAlias[alias] = l.Name
+		}
+		for _, ext := range l.Extensions {
+			byExtension[ext] = l.Name
+		}
+		for _, name := range l.Filenames {
+			byFilename[name] = l.Name
+		}
+		for _, interpreter := range l.Interpreters {
+			byInterpreter[interpreter] = l.Name
+		}
+	}
+}
+
+// Lookup resolves a language name or alias, case-insensitively.
+func Lookup(name string) (string, bool) {
+	l, ok := byAlias[strings.ToLower(strings.TrimSpace(name))]
+	return l, ok
+}
+
+// DetectName detects the language from the file name alone, returning an
+// empty string when the name is not conclusive.
+func DetectName(filePath string) string {
+	base := path.Base(filePath)
+	if l, ok := byFilename[base]; ok {
+		return l
+	}
+	if strings.HasPrefix(base, "Dockerfile.") {
+		return "Dockerfile"
+	}
+	return byExtension[strings.ToLower(path.Ext(base))]
+}
+
+// Detect detects the language from the file name, then its shebang line and
+// finally a Vim or Emacs modeline in the first or last lines of content.
+func Detect(filePath, content string) string {
+	if l := DetectName(filePath); l != "" {
+		return l
+	}
+	if l := detectShebang(content); l != "" {
+		return l
+	}
+	if l := detectModeline(content); l != "" {
+		return l
+	}
+	return Other
+}
+
+func detectShebang(content string) string {
+	if !strings.HasPrefix(content, "#!") {
+		return ""
+	}
+	line, _, _ := strings.Cut(content[2:], "\n")
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return ""
+	}
+
+	interpreter := path.Base(fields[0])
+	if interpreter == "env" {
+		// Skip env options such as -S
+		for _, field := range fields[1:] {
+			if !strings.HasPrefix(field, "-") {
+				interpreter = field
+				break
+			}
+		}
+	}
+
+	if l, ok := byInterpreter[interpreter]; ok {
+		return l
+	}
+	// Versioned interpreters such as python3.12
+	return byInterpreter[strings.TrimRight(interpreter, "0123456789.")]
+}
+
+func detectModeline(content string) string {
+	lines := strings.Split(content, "\n")
+	if len(lines) > 10 {
+		lines = append(lines[:5], lines[len(lines)-5:]...)
+	}
+	for _, line := range lines {
+		for _, re := range []*regexp.Regexp{vimModeline, emacsModeline} {
+			if m := re.FindStringSubmatch(line); m != nil {
+				if l, ok := Lookup(m[1]); ok {
+					return l
+				}
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,95 @@
+package language
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		content string
+		want    string
+	}{
+		{
+			name: "Extension",
+			path: "cmd/main.go",
+			want: "Go",
+		},
+		{
+			name: "Uppercase extension",
+			path: "README.MD",
+			want: "Markdown",
+		},
+		{
+			name: "Filename",
+			path: "build/Dockerfile",
+			want: "Dockerfile",
+		},
+		{
+			name: "Dockerfile variant",
+			path: "Dockerfile.dev",
+			want: "Dockerfile",
+		},
+		{
+			name:    "Shebang",
+			path:    "bin/deploy",
+			content: "#!/bin/bash\necho deploy\n",
+			want:    "Shell",
+		},
+		{
+			name:    "Shebang through env",
+			path:    "scripts/tool",
+			content: "#!/usr/bin/env -S python3.12 -u\nprint('hi')\n",
+			want:    "Python",
+		},
+		{
+			name:    "Vim modeline",
+			path:    "config/rules",
+			content: "rules = []\n# vim: set ft=ruby:\n",
+			want:    "Ruby",
+		},
+		{
+			name:    "Emacs modeline",
+			path:    "scripts/setup",
+			content: "# -*- mode: python -*-\nimport os\n",
+			want:    "Python",
+		},
+		{
+			name:    "Unknown",
+			path:    "data.bin",
+			content: "\x00\x01",
+			want:    Other,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Detect(tt.path, tt.content))
+		})
+	}
+}
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		name   string
+		want   string
+		wantOk bool
+	}{
+		{name: "go", want: "Go", wantOk: true},
+		{name: "ts", want: "TypeScript", wantOk: true},
+		{name: " TypeScript ", want: "TypeScript", wantOk: true},
+		{name: "c++", want: "C++", wantOk: true},
+		{name: "cobol"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Lookup(tt.name)
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
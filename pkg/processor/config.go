@@ -1,36 +1,188 @@
 package processor
 
-func NewConfig(slug, branch, dir, output string, stdout bool, include, exclude []string) (*Config, error) {
+import (
+	"fmt"
+	"runtime"
+	"strings"
+
+	"github.com/iamhectorsosa/octomap/pkg/compress"
+)
+
+func NewConfig(opts Options) (*Config, error) {
 	// GitHub Repository Details
-	if err := validateSlug(slug); err != nil {
+	if err := validateSlug(opts.Slug); err != nil {
+		return nil, err
+	}
+	if err := validateBranch(opts.Branch); err != nil {
+		return nil, err
+	}
+	repo, url, createdDir, createdDirs := createRepoDetails(opts.Slug, opts.Branch, opts.Dirs)
+	owner := strings.SplitN(opts.Slug, "/", 2)[0]
+
+	// Post-processing Workers
+	if err := validateJobs(opts.Jobs); err != nil {
 		return nil, err
 	}
-	if err := validateBranch(branch); err != nil {
+	jobs := opts.Jobs
+	if jobs == 0 {
+		jobs = runtime.NumCPU()
+	}
+
+	// File Size Limit
+	if err := validateMaxSize(opts.MaxSize); err != nil {
 		return nil, err
 	}
-	repo, url, createdDir := createRepoDetails(slug, branch, dir)
 
-	var resolvedOutput string
+	// Language Filter
+	langs, err := resolveLangs(opts.Langs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Content Filter
+	contains, err := createContains(opts.Contains)
+	if err != nil {
+		return nil, err
+	}
 
-	// Output Directory
-	if !stdout {
-		var err error
-		resolvedOutput, err = resolveOutput(output)
+	// Content Mode
+	mode := opts.Mode
+	if mode == "" {
+		mode = ModeFull
+	}
+	if err := validateMode(mode); err != nil {
+		return nil, err
+	}
+	if err := validatePatterns(opts.Full); err != nil {
+		return nil, err
+	}
+
+	// Tree Output
+	treeFormat := opts.TreeFormat
+	if treeFormat == "" {
+		treeFormat = TreeFormatJSON
+	}
+	if err := validateTreeFormat(treeFormat); err != nil {
+		return nil, err
+	}
+
+	// Report Format
+	format := opts.Format
+	if format == "" {
+		format = FormatJSON
+	}
+	if err := validateFormat(format, opts.Stdout); err != nil {
+		return nil, err
+	}
+
+	// Report Compression
+	compression := opts.Compress
+	if compression == "" {
+		compression = compress.None
+	}
+	if err := validateCompress(compression, format); err != nil {
+		return nil, err
+	}
+
+	// Git LFS Pointers
+	lfs := opts.LFS
+	if lfs == "" {
+		lfs = LFSPointer
+	}
+	if err := validateLFS(lfs); err != nil {
+		return nil, err
+	}
+
+	// Git Submodules
+	submodules := opts.Submodules
+	if submodules == "" {
+		submodules = SubmodulesSkip
+	}
+	if err := validateSubmodules(submodules); err != nil {
+		return nil, err
+	}
+	if err := validateSubmoduleDepth(opts.SubmoduleDepth); err != nil {
+		return nil, err
+	}
+
+	// Secret Redaction
+	redact := opts.Redact
+	if redact == "" {
+		redact = RedactNone
+	}
+	if err := validateRedact(redact); err != nil {
+		return nil, err
+	}
+	redactRules, err := createRedactRules(opts.RedactPatterns)
+	if err != nil {
+		return nil, err
+	}
+
+	var resolvedOutput, outputTemplate string
+
+	// Output Directory or File
+	if !opts.Stdout {
+		resolvedOutput, err = resolveOutput(opts.Output)
 		if err != nil {
 			return nil, err
 		}
-		if err := validateOutput(resolvedOutput); err != nil {
-			return nil, err
+		if isOutputFile(resolvedOutput) {
+			if err := validateOutputTemplate(resolvedOutput, opts.Deterministic); err != nil {
+				return nil, err
+			}
+			outputTemplate, resolvedOutput = resolvedOutput, ""
+		} else if !opts.MkDir {
+			if err := validateOutput(resolvedOutput); err != nil {
+				return nil, err
+			}
 		}
 	}
+	onConflict := opts.OnConflict
+	if onConflict == "" {
+		onConflict = OnConflictOverwrite
+	}
+	if err := validateOnConflict(onConflict, format); err != nil {
+		return nil, err
+	}
 
 	return &Config{
-		Repo:    repo,
-		Url:     url,
-		Dir:     createdDir,
-		Output:  resolvedOutput,
-		Stdout:  stdout,
-		Include: include,
-		Exclude: exclude,
+		Owner:          owner,
+		Repo:           repo,
+		Branch:         opts.Branch,
+		Url:            url,
+		Dir:            createdDir,
+		Dirs:           createdDirs,
+		Output:         resolvedOutput,
+		OutputTemplate: outputTemplate,
+		OnConflict:     onConflict,
+		MkDir:          opts.MkDir,
+		Stdout:         opts.Stdout,
+		Langs:          langs,
+		Include:        opts.Include,
+		Exclude:        opts.Exclude,
+		Paths:          opts.Paths,
+		Jobs:           jobs,
+		MaxSize:        opts.MaxSize,
+		ListSkipped:    opts.ListSkipped,
+		Deterministic:  opts.Deterministic,
+		FollowSymlinks: opts.FollowSymlinks,
+		Gitignore:      opts.Gitignore,
+		Mode:           mode,
+		Full:           opts.Full,
+		Minify:         opts.Minify,
+		Redact:         redact,
+		TreeOnly:       opts.TreeOnly,
+		TreeFormat:     treeFormat,
+		Format:         format,
+		Compress:       compression,
+		LFS:            lfs,
+		LFSUrl:         fmt.Sprintf(githubLFS, owner, repo),
+		Submodules:     submodules,
+		SubmoduleDepth: opts.SubmoduleDepth,
+		GithubUrl:      githubUrl,
+		ApiUrl:         githubApi,
+		Token:          opts.Token,
+		RedactRules:    redactRules,
+		Contains:       contains,
 	}, nil
 }
@@ -3,8 +3,14 @@ package processor
 import (
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
+
+	"github.com/iamhectorsosa/octomap/pkg/compress"
+	"github.com/iamhectorsosa/octomap/pkg/language"
 )
 
 const (
@@ -12,7 +18,26 @@ const (
 
 	invalidUserRepoTxt   = "invalid [user/repo] input, received %q\n"
 	invalidBranchName    = "invalid branch, received %q\n"
-	invalidOutputWithExt = "invalid output, cannot contain extension, received %q\n"
+	invalidOutputHolder  = "invalid output, unknown placeholder %q, must be one of %s\n"
+	invalidOutputClock   = "invalid output, placeholder %q cannot be used with deterministic\n"
+	invalidOnConflict    = "invalid on-conflict, must be one of %s, received %q\n"
+	invalidAppend        = "invalid on-conflict, append requires the %s format\n"
+	invalidJobs          = "invalid jobs, cannot be negative, received %d\n"
+	invalidMaxSize       = "invalid max size, cannot be negative, received %d\n"
+	invalidLang          = "invalid language, received %q\n"
+	invalidMode          = "invalid mode, must be one of %s, received %q\n"
+	invalidPattern       = "invalid pattern, received %q\n%v\n"
+	invalidTreeFormat    = "invalid tree format, must be one of %s, received %q\n"
+	invalidFormat        = "invalid format, must be one of %s, received %q\n"
+	invalidFormatStdout  = "invalid format, %q cannot be used with stdout\n"
+	invalidCompress      = "invalid compress, must be one of %s, received %q\n"
+	invalidCompressDB    = "invalid compress, cannot be used with the %s format\n"
+	invalidLFS           = "invalid lfs, must be one of %s, received %q\n"
+	invalidSubmodules    = "invalid submodules, must be one of %s, received %q\n"
+	invalidDepth         = "invalid submodule depth, cannot be negative, received %d\n"
+	invalidRedact        = "invalid redact, must be one of %s, received %q\n"
+	invalidRedactPattern = "invalid redact pattern, received %q\n%v\n"
+	invalidContains      = "invalid contains pattern, received %q\n%v\n"
 
 	errHomeDirectory    = "failed to get user home directory, %v\n"
 	errOutputDoesntExit = "output path does not exist, received %q\n%v\n"
@@ -39,7 +64,128 @@ func validateBranch(branch string) error {
 	return nil
 }
 
-func createRepoDetails(slug string, branch, inputDir string) (repo, url, dir string) {
+func validateJobs(jobs int) error {
+	if jobs < 0 {
+		return fmt.Errorf(invalidJobs, jobs)
+	}
+	return nil
+}
+
+func validateMaxSize(maxSize int) error {
+	if maxSize < 0 {
+		return fmt.Errorf(invalidMaxSize, maxSize)
+	}
+	return nil
+}
+
+// resolveLangs maps language names and aliases to their canonical names.
+func resolveLangs(langs []string) ([]string, error) {
+	var resolved []string
+	for _, lang := range langs {
+		name, ok := language.Lookup(lang)
+		if !ok {
+			return nil, fmt.Errorf(invalidLang, lang)
+		}
+		if !slices.Contains(resolved, name) {
+			resolved = append(resolved, name)
+		}
+	}
+	return resolved, nil
+}
+
+func validateMode(mode string) error {
+	if !slices.Contains(modes, mode) {
+		return fmt.Errorf(invalidMode, strings.Join(modes, "|"), mode)
+	}
+	return nil
+}
+
+func validatePatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf(invalidPattern, pattern, err)
+		}
+	}
+	return nil
+}
+
+func validateTreeFormat(format string) error {
+	if !slices.Contains(treeFormats, format) {
+		return fmt.Errorf(invalidTreeFormat, strings.Join(treeFormats, "|"), format)
+	}
+	return nil
+}
+
+func validateFormat(format string, stdout bool) error {
+	if !slices.Contains(formats, format) {
+		return fmt.Errorf(invalidFormat, strings.Join(formats, "|"), format)
+	}
+	if stdout && format != FormatJSON {
+		return fmt.Errorf(invalidFormatStdout, format)
+	}
+	return nil
+}
+
+func validateCompress(method, format string) error {
+	if !slices.Contains(compress.Methods, method) {
+		return fmt.Errorf(invalidCompress, strings.Join(compress.Methods, "|"), method)
+	}
+	if method != compress.None && format == FormatSQLite {
+		return fmt.Errorf(invalidCompressDB, format)
+	}
+	return nil
+}
+
+func validateLFS(mode string) error {
+	if !slices.Contains(lfsModes, mode) {
+		return fmt.Errorf(invalidLFS, strings.Join(lfsModes, "|"), mode)
+	}
+	return nil
+}
+
+func validateSubmodules(mode string) error {
+	if !slices.Contains(submoduleModes, mode) {
+		return fmt.Errorf(invalidSubmodules, strings.Join(submoduleModes, "|"), mode)
+	}
+	return nil
+}
+
+func validateSubmoduleDepth(depth int) error {
+	if depth < 0 {
+		return fmt.Errorf(invalidDepth, depth)
+	}
+	return nil
+}
+
+func validateRedact(redact string) error {
+	if !slices.Contains(redactModes, redact) {
+		return fmt.Errorf(invalidRedact, strings.Join(redactModes, "|"), redact)
+	}
+	return nil
+}
+
+// createRedactRules returns the built-in rules followed by one rule per
+// custom pattern, named custom-1, custom-2 and so on.
+func createRedactRules(patterns []string) ([]RedactRule, error) {
+	rules := slices.Clone(builtinRedactRules)
+	for i, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf(invalidRedactPattern, pattern, err)
+		}
+		rules = append(rules, RedactRule{
+			Name:    fmt.Sprintf("custom-%d", i+1),
+			Pattern: re,
+		})
+	}
+	return rules, nil
+}
+
+// createRepoDetails resolves the archive URL and the directories to map. A
+// single input directory becomes the root that paths are relative to. With
+// several, paths stay relative to the repository root and dirs lists the
+// archive prefixes entries must fall under.
+func createRepoDetails(slug string, branch string, inputDirs []string) (repo, url, dir string, dirs []string) {
 	validatedSlug := strings.SplitN(slug, "/", 2)
 	user := validatedSlug[0]
 	repo = validatedSlug[1]
@@ -47,23 +193,45 @@ func createRepoDetails(slug string, branch, inputDir string) (repo, url, dir str
 	url = fmt.Sprintf(githubArchive, user, repo, branch)
 
 	dir = fmt.Sprintf("%s-%s", repo, branch)
-	if inputDir != "" {
-		dir += "/" + inputDir
+
+	var cleanDirs []string
+	for _, inputDir := range inputDirs {
+		cleanDir := strings.Trim(path.Clean("/"+inputDir), "/")
+		if cleanDir == "" || slices.Contains(cleanDirs, cleanDir) {
+			continue
+		}
+		cleanDirs = append(cleanDirs, cleanDir)
+	}
+
+	if len(cleanDirs) == 1 {
+		dir += "/" + cleanDirs[0]
+		return
+	}
+
+	for _, cleanDir := range cleanDirs {
+		dirs = append(dirs, dir+"/"+cleanDir)
 	}
 
 	return
 }
 
+// createContains compiles the content filter, nil when there is none.
+func createContains(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf(invalidContains, pattern, err)
+	}
+	return re, nil
+}
+
 func validateOutput(output string) error {
 	if output == "" {
 		return nil
 	}
 
-	ext := filepath.Ext(output)
-	if ext != "" {
-		return fmt.Errorf(invalidOutputWithExt, output)
-	}
-
 	_, err := os.Stat(output)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -75,6 +243,31 @@ func validateOutput(output string) error {
 	return nil
 }
 
+// validateOutputTemplate checks the placeholders of an output file template.
+// Deterministic reports cannot be named after the wall clock.
+func validateOutputTemplate(template string, deterministic bool) error {
+	for _, match := range placeholderPattern.FindAllStringSubmatch(template, -1) {
+		name := match[1]
+		if !slices.Contains(outputPlaceholders, name) {
+			return fmt.Errorf(invalidOutputHolder, name, strings.Join(outputPlaceholders, "|"))
+		}
+		if deterministic && slices.Contains(clockPlaceholders, name) {
+			return fmt.Errorf(invalidOutputClock, name)
+		}
+	}
+	return nil
+}
+
+func validateOnConflict(policy, format string) error {
+	if !slices.Contains(conflictPolicies, policy) {
+		return fmt.Errorf(invalidOnConflict, strings.Join(conflictPolicies, "|"), policy)
+	}
+	if policy == OnConflictAppend && format != FormatSQLite {
+		return fmt.Errorf(invalidAppend, FormatSQLite)
+	}
+	return nil
+}
+
 func resolveOutput(output string) (string, error) {
 	if output == "" {
 		return output, nil
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/iamhectorsosa/octomap/pkg/compress"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -89,20 +90,233 @@ func TestValidateBranch(t *testing.T) {
 	}
 }
 
+func TestValidateJobs(t *testing.T) {
+	tests := []struct {
+		err  error
+		name string
+		jobs int
+	}{
+		{
+			name: "Default jobs",
+			jobs: 0,
+		},
+		{
+			name: "Positive jobs",
+			jobs: 8,
+		},
+		{
+			name: "Negative jobs",
+			jobs: -1,
+			err:  fmt.Errorf(invalidJobs, -1),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateJobs(tt.jobs)
+			if tt.err != nil {
+				assert.Error(t, err)
+				assert.EqualError(t, err, tt.err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateMaxSize(t *testing.T) {
+	tests := []struct {
+		err     error
+		name    string
+		maxSize int
+	}{
+		{
+			name:    "No limit",
+			maxSize: 0,
+		},
+		{
+			name:    "Positive max size",
+			maxSize: 1 << 20,
+		},
+		{
+			name:    "Negative max size",
+			maxSize: -1,
+			err:     fmt.Errorf(invalidMaxSize, -1),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateMaxSize(tt.maxSize)
+			if tt.err != nil {
+				assert.Error(t, err)
+				assert.EqualError(t, err, tt.err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateFormat(t *testing.T) {
+	tests := []struct {
+		err    error
+		name   string
+		format string
+		stdout bool
+	}{
+		{
+			name:   "JSON",
+			format: FormatJSON,
+			stdout: true,
+		},
+		{
+			name:   "SQLite",
+			format: FormatSQLite,
+		},
+		{
+			name:   "SQLite to stdout",
+			format: FormatSQLite,
+			stdout: true,
+			err:    fmt.Errorf(invalidFormatStdout, FormatSQLite),
+		},
+		{
+			name:   "Unknown format",
+			format: "csv",
+			err:    fmt.Errorf(invalidFormat, "json|sqlite", "csv"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateFormat(tt.format, tt.stdout)
+			if tt.err != nil {
+				assert.Error(t, err)
+				assert.EqualError(t, err, tt.err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateCompress(t *testing.T) {
+	assert.NoError(t, validateCompress(compress.None, FormatSQLite))
+	assert.NoError(t, validateCompress(compress.Zstd, FormatJSON))
+	assert.EqualError(t, validateCompress("brotli", FormatJSON), fmt.Sprintf(invalidCompress, "none|gzip|zstd", "brotli"))
+	assert.EqualError(t, validateCompress(compress.Gzip, FormatSQLite), fmt.Sprintf(invalidCompressDB, FormatSQLite))
+}
+
+func TestValidateLFS(t *testing.T) {
+	assert.NoError(t, validateLFS(LFSResolve))
+	assert.EqualError(t, validateLFS("fetch"), fmt.Sprintf(invalidLFS, "pointer|skip|resolve", "fetch"))
+}
+
+func TestValidateSubmodules(t *testing.T) {
+	assert.NoError(t, validateSubmodules(SubmodulesExpand))
+	assert.EqualError(t, validateSubmodules("clone"), fmt.Sprintf(invalidSubmodules, "skip|pointer|expand", "clone"))
+	assert.NoError(t, validateSubmoduleDepth(0))
+	assert.EqualError(t, validateSubmoduleDepth(-1), fmt.Sprintf(invalidDepth, -1))
+}
+
+func TestResolveLangs(t *testing.T) {
+	langs, err := resolveLangs([]string{"go", "ts", "Golang"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Go", "TypeScript"}, langs)
+
+	_, err = resolveLangs([]string{"cobol"})
+	assert.EqualError(t, err, fmt.Sprintf(invalidLang, "cobol"))
+}
+
+func TestValidateMode(t *testing.T) {
+	tests := []struct {
+		err  error
+		name string
+		mode string
+	}{
+		{
+			name: "Full mode",
+			mode: ModeFull,
+		},
+		{
+			name: "Outline mode",
+			mode: ModeOutline,
+		},
+		{
+			name: "Unknown mode",
+			mode: "summary",
+			err:  fmt.Errorf(invalidMode, "full|outline", "summary"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateMode(tt.mode)
+			if tt.err != nil {
+				assert.Error(t, err)
+				assert.EqualError(t, err, tt.err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidatePatterns(t *testing.T) {
+	assert.NoError(t, validatePatterns([]string{"*_test.go", "cmd/*/main.go"}))
+	assert.Error(t, validatePatterns([]string{"[main.go"}))
+}
+
+func TestValidateRedact(t *testing.T) {
+	assert.NoError(t, validateRedact(RedactMask))
+	assert.EqualError(t, validateRedact("hide"), fmt.Sprintf(invalidRedact, "none|mask|drop-file|fail", "hide"))
+}
+
 func TestCreateRepoDetails(t *testing.T) {
 	slug := "user/repo"
 	branch := "main"
-	inputDir := "src"
 
 	expectedRepo := "repo"
 	expectedURL := "https://github.com/user/repo/archive/refs/heads/main.tar.gz"
-	expectedDir := "repo-main/src"
 
-	repo, url, dir := createRepoDetails(slug, branch, inputDir)
+	tests := []struct {
+		name      string
+		inputDirs []string
+		wantDir   string
+		wantDirs  []string
+	}{
+		{
+			name:    "No directory",
+			wantDir: "repo-main",
+		},
+		{
+			name:      "Single directory",
+			inputDirs: []string{"src"},
+			wantDir:   "repo-main/src",
+		},
+		{
+			name:      "Single directory with slashes",
+			inputDirs: []string{"./src/", "src"},
+			wantDir:   "repo-main/src",
+		},
+		{
+			name:      "Multiple directories",
+			inputDirs: []string{"api", "pkg/auth/", "../api"},
+			wantDir:   "repo-main",
+			wantDirs:  []string{"repo-main/api", "repo-main/pkg/auth"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, url, dir, dirs := createRepoDetails(slug, branch, tt.inputDirs)
 
-	assert.Equal(t, expectedRepo, repo)
-	assert.Equal(t, expectedURL, url)
-	assert.Equal(t, expectedDir, dir)
+			assert.Equal(t, expectedRepo, repo)
+			assert.Equal(t, expectedURL, url)
+			assert.Equal(t, tt.wantDir, dir)
+			assert.Equal(t, tt.wantDirs, dirs)
+		})
+	}
 }
 
 func TestValidateOutput(t *testing.T) {
@@ -131,11 +345,6 @@ func TestValidateOutput(t *testing.T) {
 			name:   "valid directory path",
 			output: testDir,
 		},
-		{
-			name:   "output with extension",
-			output: "test.txt",
-			err:    fmt.Errorf(invalidOutputWithExt, "test.txt"),
-		},
 		{
 			name:   "non-existent directory",
 			output: filepath.Join(tmpDir, "nonexistst"),
@@ -160,6 +369,57 @@ func TestValidateOutput(t *testing.T) {
 	}
 }
 
+func TestValidateOutputTemplate(t *testing.T) {
+	tests := []struct {
+		err           error
+		name          string
+		template      string
+		deterministic bool
+	}{
+		{
+			name:     "file path",
+			template: "reports/octomap.json",
+		},
+		{
+			name:     "template",
+			template: "{owner}/{repo}@{ref}-{date}.{ext}",
+		},
+		{
+			name:     "unknown placeholder",
+			template: "{repo}-{user}.json",
+			err:      fmt.Errorf(invalidOutputHolder, "user", "owner|repo|ref|commit|date|time|ext"),
+		},
+		{
+			name:          "deterministic template",
+			template:      "{repo}@{commit}.{ext}",
+			deterministic: true,
+		},
+		{
+			name:          "deterministic with date",
+			template:      "{repo}-{date}.{ext}",
+			deterministic: true,
+			err:           fmt.Errorf(invalidOutputClock, "date"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateOutputTemplate(tt.template, tt.deterministic)
+			if tt.err != nil {
+				assert.Error(t, err)
+				assert.EqualError(t, err, tt.err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	assert.NoError(t, validateOnConflict(OnConflictSuffix, FormatJSON))
+	assert.NoError(t, validateOnConflict(OnConflictAppend, FormatSQLite))
+	assert.EqualError(t, validateOnConflict("rename", FormatJSON), fmt.Sprintf(invalidOnConflict, "overwrite|suffix|fail|append", "rename"))
+	assert.EqualError(t, validateOnConflict(OnConflictAppend, FormatJSON), fmt.Sprintf(invalidAppend, FormatSQLite))
+}
+
 func TestResolveOuput(t *testing.T) {
 	origEnv := os.Getenv("TEST_VAR")
 	defer os.Setenv("TEST_VAR", origEnv)
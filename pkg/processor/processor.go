@@ -2,21 +2,99 @@ package processor
 
 import (
 	"fmt"
-	"time"
+	"slices"
+	"sort"
 )
 
 func New(config *Config, ch chan<- Update) *Processor {
-	return &Processor{
+	p := &Processor{
 		config:        config,
 		data:          make(RepositoryData),
+		languages:     make(map[string]*LanguageStats),
 		ch:            ch,
 		dirCount:      0,
 		fileCount:     0,
 		dataFileCount: 0,
 	}
+
+	if len(config.Paths) > 0 {
+		p.paths = make(map[string]bool, len(config.Paths))
+		for _, path := range config.Paths {
+			p.paths[path] = true
+		}
+	}
+
+	// Line endings are normalized first so every later step sees the same
+	// content on any platform
+	if config.Deterministic {
+		p.transforms = append(p.transforms, p.normalizeLineEndings)
+	}
+
+	// Languages are detected on the original content. Transforms that shape
+	// content run before redaction, so only what is written out gets scanned
+	p.transforms = append(p.transforms, p.detectLanguage)
+	if config.TreeOnly {
+		return p
+	}
+	if config.Mode == ModeOutline {
+		p.transforms = append(p.transforms, p.outline)
+	}
+	if config.Minify {
+		p.transforms = append(p.transforms, p.minify)
+	}
+	if config.Redact != "" && config.Redact != RedactNone {
+		p.transforms = append(p.transforms, p.redact)
+	}
+
+	return p
+}
+
+// Manifest describes the report generated by the processor.
+func (p *Processor) Manifest() Manifest {
+	manifest := Manifest{
+		Repo:   p.config.Repo,
+		Url:    p.config.Url,
+		Commit: p.commit,
+		Dir:    p.config.Dir,
+		Dirs:   p.config.Dirs,
+		Stats:  p.Stats(),
+	}
+	if p.config.ListSkipped {
+		manifest.Skipped = p.Skipped()
+	}
+	if len(p.lfs) > 0 {
+		manifest.LFS = slices.Clone(p.lfs)
+		sort.SliceStable(manifest.LFS, func(i, j int) bool {
+			return manifest.LFS[i].Path < manifest.LFS[j].Path
+		})
+	}
+	if len(p.links) > 0 {
+		manifest.Links = slices.Clone(p.links)
+		sort.SliceStable(manifest.Links, func(i, j int) bool {
+			return manifest.Links[i].Path < manifest.Links[j].Path
+		})
+	}
+	if len(p.submodules) > 0 {
+		manifest.Submodules = slices.Clone(p.submodules)
+		sort.SliceStable(manifest.Submodules, func(i, j int) bool {
+			return manifest.Submodules[i].Path < manifest.Submodules[j].Path
+		})
+	}
+	return manifest
+}
+
+// Redactions returns the secrets found while processing, in output order.
+func (p *Processor) Redactions() []Redaction {
+	return p.redactions
+}
+
+// ReportPath returns the path of the written report, empty until the report
+// is saved or when writing to stdout.
+func (p *Processor) ReportPath() string {
+	return p.reportPath
 }
 
-func (p *Processor) Process(stagger time.Duration) (RepositoryData, error) {
+func (p *Processor) Process() (RepositoryData, error) {
 	if p.ch != nil {
 		defer close(p.ch)
 	}
@@ -28,13 +106,33 @@ func (p *Processor) Process(stagger time.Duration) (RepositoryData, error) {
 	}
 	defer reader.Close()
 
-	if err := p.read(reader, stagger); err != nil {
+	if err := p.read(reader); err != nil {
+		p.updateError(err)
+		return nil, err
+	}
+	if err := p.mapSubmodules(); err != nil {
+		p.updateError(err)
+		return nil, err
+	}
+	if err := p.followLinks(); err != nil {
 		p.updateError(err)
 		return nil, err
 	}
 
 	p.update(fmt.Sprintf("found: %d directories and %d files", p.dirCount, p.fileCount))
-	p.update(fmt.Sprintf("prepared: %d out of %d files for report", p.dataFileCount, p.fileCount))
+	stats := p.Stats()
+	p.updateStats(fmt.Sprintf("prepared: %d out of %d files for report", p.dataFileCount, p.fileCount), &stats)
+	if p.originalTokens > 0 {
+		p.update(fmt.Sprintf(
+			"minified: %d to %d estimated tokens, saved %d%%",
+			p.originalTokens,
+			p.originalTokens-p.savedTokens,
+			100*p.savedTokens/p.originalTokens,
+		))
+	}
+	if len(p.redactions) > 0 {
+		p.update(fmt.Sprintf("redacted: %d secrets", len(p.redactions)))
+	}
 
 	if !p.config.Stdout {
 		if err := p.save(); err != nil {
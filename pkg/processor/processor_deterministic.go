@@ -0,0 +1,50 @@
+package processor
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// commitLen is the length of the commit ID in deterministic file names.
+const commitLen = 12
+
+// baseName returns the name reports are saved under, without extension.
+// Deterministic names only depend on the repository, the branch and the
+// archived commit, so the same snapshot is always saved to the same files.
+func (p *Processor) baseName() string {
+	if !p.config.Deterministic {
+		return fmt.Sprintf("%s%s", p.config.Repo, time.Now().Format("20060102_150405"))
+	}
+
+	parts := []string{p.config.Repo}
+	if p.config.Branch != "" {
+		parts = append(parts, strings.ReplaceAll(p.config.Branch, "/", "-"))
+	}
+	if p.commit != "" {
+		parts = append(parts, p.commit[:min(commitLen, len(p.commit))])
+	}
+	return strings.Join(parts, "_")
+}
+
+// normalizeLineEndings converts CRLF and lone CR line endings to LF.
+func (p *Processor) normalizeLineEndings(f *File) error {
+	if strings.Contains(f.Content, "\r") {
+		f.Content = strings.ReplaceAll(f.Content, "\r\n", "\n")
+		f.Content = strings.ReplaceAll(f.Content, "\r", "\n")
+	}
+	return nil
+}
+
+// sortOutput orders the redactions by path, so output does not depend on the
+// order of the archive.
+func (p *Processor) sortOutput() {
+	sort.SliceStable(p.redactions, func(i, j int) bool {
+		a, b := p.redactions[i], p.redactions[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Line < b.Line
+	})
+}
@@ -0,0 +1,118 @@
+package processor
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testCommit = "0123456789abcdef0123456789abcdef01234567"
+
+// newGitArchive writes files in the given order after a pax global header
+// carrying the commit, like git archive does.
+func newGitArchive(t *testing.T, commit string, files [][2]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+
+	require.NoError(t, tw.WriteHeader(&tar.Header{
+		Typeflag:   tar.TypeXGlobalHeader,
+		Name:       "pax_global_header",
+		PAXRecords: map[string]string{"comment": commit},
+	}))
+	for _, file := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: file[0], Mode: 0600, Size: int64(len(file[1]))}))
+		_, err := tw.Write([]byte(file[1]))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, tw.Close())
+	require.NoError(t, gw.Close())
+	return buf.Bytes()
+}
+
+func TestProcessDeterministic(t *testing.T) {
+	files := [][2]string{
+		{"repo-main/main.go", "package main\r\n\r\nfunc main() {}\r\n"},
+		{"repo-main/docs/README.md", "# Docs\n"},
+		{"repo-main/api/server.go", "package api\n"},
+	}
+	reversed := [][2]string{files[2], files[1], files[0]}
+
+	var archive []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(archive)
+	}))
+	defer server.Close()
+
+	// process maps the archive and returns the saved files by name
+	process := func(t *testing.T, format string) (*Processor, map[string][]byte) {
+		tmpDir := t.TempDir()
+		p := New(&Config{
+			Repo:          "test-repo",
+			Branch:        "feature/x",
+			Url:           server.URL,
+			Dir:           "repo-main",
+			Output:        tmpDir,
+			Format:        format,
+			Jobs:          4,
+			Deterministic: true,
+		}, nil)
+		_, err := p.Process()
+		require.NoError(t, err)
+
+		entries, err := os.ReadDir(tmpDir)
+		require.NoError(t, err)
+		saved := make(map[string][]byte)
+		for _, entry := range entries {
+			content, err := os.ReadFile(filepath.Join(tmpDir, entry.Name()))
+			require.NoError(t, err)
+			saved[entry.Name()] = content
+		}
+		return p, saved
+	}
+
+	t.Run("json", func(t *testing.T) {
+		archive = newGitArchive(t, testCommit, files)
+		p, first := process(t, FormatJSON)
+		archive = newGitArchive(t, testCommit, reversed)
+		_, second := process(t, FormatJSON)
+
+		assert.Equal(t, "test-repo_feature-x_0123456789ab.json", filepath.Base(p.ReportPath()))
+		assert.Equal(t, testCommit, p.Manifest().Commit)
+		assert.Contains(t, string(first["test-repo_feature-x_0123456789ab.json"]), `"package main\n\nfunc main() {}\n"`)
+		assert.Len(t, first, 2)
+		assert.Equal(t, first, second)
+	})
+
+	t.Run("sqlite", func(t *testing.T) {
+		archive = newGitArchive(t, testCommit, files)
+		_, first := process(t, FormatSQLite)
+		archive = newGitArchive(t, testCommit, reversed)
+		_, second := process(t, FormatSQLite)
+
+		assert.Contains(t, first, "test-repo_feature-x_0123456789ab.db")
+		assert.Equal(t, first, second)
+	})
+}
+
+func TestBaseName(t *testing.T) {
+	p := New(&Config{Repo: "repo", Branch: "main", Deterministic: true}, nil)
+	assert.Equal(t, "repo_main", p.baseName())
+
+	p.commit = testCommit
+	assert.Equal(t, "repo_main_0123456789ab", p.baseName())
+
+	p.config.Deterministic = false
+	assert.Regexp(t, `^repo\d{8}_\d{6}$`, p.baseName())
+}
@@ -0,0 +1,149 @@
+package processor
+
+import (
+	"path"
+	"strings"
+)
+
+const gitignoreFile = ".gitignore"
+
+// ignoreRule is a pattern of a .gitignore file, with pattern as written.
+type ignoreRule struct {
+	pattern  string
+	segments []string
+	negate   bool
+	dirOnly  bool
+	anchored bool
+}
+
+// pendingFile is a file read before the .gitignore file of the repository.
+type pendingFile struct {
+	file     *File
+	repoPath string
+}
+
+// parseGitignore returns the patterns of the content of a .gitignore file,
+// in file order.
+func parseGitignore(content string) []ignoreRule {
+	var rules []ignoreRule
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.TrimSpace(line) == "" || line[0] == '#' {
+			continue
+		}
+		// Trailing spaces are ignored unless escaped
+		for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, `\ `) {
+			line = line[:len(line)-1]
+		}
+
+		rule := ignoreRule{pattern: line}
+		if line[0] == '!' {
+			rule.negate = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+
+		// Patterns with a slash other than a trailing one are relative to
+		// the root, others match a name at any level
+		rule.anchored = strings.Contains(line, "/")
+		rule.segments = strings.Split(strings.TrimPrefix(line, "/"), "/")
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// matches reports whether the rule matches the path split in parts.
+func (r ignoreRule) matches(parts []string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+	if !r.anchored {
+		ok, _ := path.Match(r.segments[0], parts[len(parts)-1])
+		return ok
+	}
+	return matchSegments(r.segments, parts)
+}
+
+// matchSegments matches path segments against pattern segments, where **
+// stands for any number of segments.
+func matchSegments(pattern, parts []string) bool {
+	if len(pattern) == 0 {
+		return len(parts) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(parts); i++ {
+			if matchSegments(pattern[1:], parts[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(parts) == 0 {
+		return false
+	}
+	ok, _ := path.Match(pattern[0], parts[0])
+	return ok && matchSegments(pattern[1:], parts[1:])
+}
+
+// gitignored returns the pattern of the root .gitignore file ignoring a path
+// relative to the repository root. As in Git, files below an ignored
+// directory are ignored whatever the later patterns, and the last pattern
+// matching a path decides.
+func (p *Processor) gitignored(repoPath string) (string, bool) {
+	parts := strings.Split(repoPath, "/")
+	for i := range parts {
+		var match *ignoreRule
+		for j := range p.ignoreRules {
+			if p.ignoreRules[j].matches(parts[:i+1], i < len(parts)-1) {
+				match = &p.ignoreRules[j]
+			}
+		}
+		if match != nil && !match.negate {
+			return match.pattern, true
+		}
+	}
+	return "", false
+}
+
+// pastGitignore reports whether an archive entry comes after the root
+// .gitignore file. Archives list entries in Git tree order, where
+// directories sort as their name followed by a slash, so files such as
+// .github/workflows/ci.yml are read before it.
+func pastGitignore(repoPath string) bool {
+	top, _, isDir := strings.Cut(repoPath, "/")
+	if top == "" {
+		return false
+	}
+	if isDir {
+		top += "/"
+	}
+	return top > gitignoreFile
+}
+
+// settleGitignore applies the root .gitignore file, once read or known to be
+// missing, to the files read before it, passing the others to emit until it
+// returns false.
+func (p *Processor) settleGitignore(emit func(f *File) bool) bool {
+	p.ignoreSettled = true
+	pending := p.ignorePending
+	p.ignorePending = nil
+
+	for _, pf := range pending {
+		if rule, ok := p.gitignored(pf.repoPath); ok {
+			p.skip(pf.file.Path, SkipGitignored, rule)
+			continue
+		}
+		if !p.submit(pf.file, emit) {
+			return false
+		}
+	}
+	return true
+}
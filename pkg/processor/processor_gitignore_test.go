@@ -0,0 +1,146 @@
+package processor
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitignored(t *testing.T) {
+	p := &Processor{ignoreRules: parseGitignore(`# build output
+dist/
+*.log
+!keep.log
+/config.json
+docs/**/*.pdf
+**/fixtures
+\#notes
+vendor/
+!vendor/keep.go
+`)}
+
+	tests := []struct {
+		path string
+		want string
+		ok   bool
+	}{
+		{path: "dist/app.js", want: "dist/", ok: true},
+		{path: "web/dist/app.js", want: "dist/", ok: true},
+		{path: "dist", ok: false},
+		{path: "debug.log", want: "*.log", ok: true},
+		{path: "logs/debug.log", want: "*.log", ok: true},
+		{path: "keep.log", ok: false},
+		{path: "config.json", want: "/config.json", ok: true},
+		{path: "web/config.json", ok: false},
+		{path: "docs/guide.pdf", want: "docs/**/*.pdf", ok: true},
+		{path: "docs/api/guide.pdf", want: "docs/**/*.pdf", ok: true},
+		{path: "web/docs/guide.pdf", ok: false},
+		{path: "test/fixtures/data.json", want: "**/fixtures", ok: true},
+		{path: "#notes", want: `\#notes`, ok: true},
+		{path: "vendor/keep.go", want: "vendor/", ok: true},
+		{path: "main.go", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, ok := p.gitignored(tt.path)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPastGitignore(t *testing.T) {
+	assert.False(t, pastGitignore(""))
+	assert.False(t, pastGitignore(".github/"))
+	assert.False(t, pastGitignore(".github/workflows/ci.yml"))
+	assert.False(t, pastGitignore(".editorconfig"))
+	assert.False(t, pastGitignore(".gitignore"))
+	assert.True(t, pastGitignore(".gitmodules"))
+	assert.True(t, pastGitignore("main.go"))
+	assert.True(t, pastGitignore("dist/app.js"))
+}
+
+func TestProcessGitignore(t *testing.T) {
+	newArchive := func(files [][2]string) []byte {
+		var buf bytes.Buffer
+		gw := gzip.NewWriter(&buf)
+		tw := tar.NewWriter(gw)
+		for _, file := range files {
+			require.NoError(t, tw.WriteHeader(&tar.Header{Name: file[0], Mode: 0644, Size: int64(len(file[1]))}))
+			tw.Write([]byte(file[1]))
+		}
+		require.NoError(t, tw.Close())
+		require.NoError(t, gw.Close())
+		return buf.Bytes()
+	}
+
+	// Entries in Git tree order, with .github read before .gitignore
+	archives := map[string][]byte{
+		"/ignore": newArchive([][2]string{
+			{"repo-main/.github/build.log", "ok\n"},
+			{"repo-main/.github/ci.yml", "on: push\n"},
+			{"repo-main/.gitignore", "*.log\ndist/\n"},
+			{"repo-main/dist/app.js", "bundle\n"},
+			{"repo-main/main.go", "package main\n"},
+		}),
+		"/none": newArchive([][2]string{
+			{"repo-main/.github/build.log", "ok\n"},
+			{"repo-main/main.go", "package main\n"},
+		}),
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(archives[r.URL.Path])
+	}))
+	defer server.Close()
+
+	newProcessor := func(archive string, gitignore bool) *Processor {
+		return New(&Config{
+			Repo:        "test-repo",
+			Url:         server.URL + archive,
+			Dir:         "repo-main",
+			Stdout:      true,
+			ListSkipped: true,
+			Gitignore:   gitignore,
+		}, nil)
+	}
+
+	t.Run("gitignore", func(t *testing.T) {
+		p := newProcessor("/ignore", true)
+		got, err := p.Process()
+		require.NoError(t, err)
+		assert.Equal(t, RepositoryData{
+			".github":    map[string]interface{}{"ci.yml": "on: push\n"},
+			".gitignore": "*.log\ndist/\n",
+			"main.go":    "package main\n",
+		}, got)
+		assert.Equal(t, []Skip{
+			{Path: ".github/build.log", Reason: SkipGitignored, Rule: "*.log"},
+			{Path: "dist/app.js", Reason: SkipGitignored, Rule: "dist/"},
+		}, p.Manifest().Skipped)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		p := newProcessor("/ignore", false)
+		got, err := p.Process()
+		require.NoError(t, err)
+		assert.Len(t, got, 4)
+		assert.Empty(t, p.Manifest().Skipped)
+	})
+
+	t.Run("no gitignore", func(t *testing.T) {
+		p := newProcessor("/none", true)
+		got, err := p.Process()
+		require.NoError(t, err)
+		assert.Equal(t, RepositoryData{
+			".github": map[string]interface{}{"build.log": "ok\n"},
+			"main.go": "package main\n",
+		}, got)
+	})
+}
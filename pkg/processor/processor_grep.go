@@ -0,0 +1,101 @@
+package processor
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/iamhectorsosa/octomap/pkg/archive"
+)
+
+// GrepLine is a line of a grep result, either matching or shown as context.
+type GrepLine struct {
+	Text   string
+	Number int
+	Match  bool
+}
+
+// GrepResult holds the matching lines of a file with their context, in line
+// order.
+type GrepResult struct {
+	Path  string
+	Lines []GrepLine
+}
+
+// Grep streams the archive through the configured filters and calls fn for
+// every file with lines matching re, in archive order. Up to context lines
+// are included before and after each match. Files are searched as they are
+// in the repository, before any transform.
+func (p *Processor) Grep(re *regexp.Regexp, context int, fn func(GrepResult) error) error {
+	if p.ch != nil {
+		defer close(p.ch)
+	}
+
+	reader, err := p.download()
+	if err != nil {
+		p.updateError(err)
+		return err
+	}
+	defer reader.Close()
+
+	tarReader, err := archive.NewTarGzReader(reader)
+	if err != nil {
+		p.updateError(err)
+		return err
+	}
+	defer tarReader.Close()
+
+	var fnErr error
+	err = p.readEntries(tarReader, func(f *File) bool {
+		lines := grepLines(f.Content, re, context)
+		if len(lines) == 0 {
+			return true
+		}
+		fnErr = fn(GrepResult{Path: f.Path, Lines: lines})
+		return fnErr == nil
+	})
+	if err == nil {
+		err = fnErr
+	}
+	if err != nil {
+		p.updateError(err)
+	}
+	return err
+}
+
+// grepLines returns the lines of content matching re, with up to context
+// lines around each match.
+func grepLines(content string, re *regexp.Regexp, context int) []GrepLine {
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+
+	var matches []int
+	for i, line := range lines {
+		if re.MatchString(line) {
+			matches = append(matches, i)
+		}
+	}
+	if len(matches) == 0 {
+		return nil
+	}
+
+	var result []GrepLine
+	next := 0 // first line not yet added
+	for k, i := range matches {
+		start := max(i-context, next)
+		for j := start; j < i; j++ {
+			result = append(result, GrepLine{Text: lines[j], Number: j + 1})
+		}
+		result = append(result, GrepLine{Text: lines[i], Number: i + 1, Match: true})
+		next = i + 1
+
+		// Context after stops at the next match, which adds its own
+		end := min(i+context, len(lines)-1)
+		if k+1 < len(matches) {
+			end = min(end, matches[k+1]-1)
+		}
+		for j := next; j <= end; j++ {
+			result = append(result, GrepLine{Text: lines[j], Number: j + 1})
+		}
+		next = max(next, end+1)
+	}
+	return result
+}
@@ -0,0 +1,95 @@
+package processor
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGrepLines(t *testing.T) {
+	content := "one\ntwo\nthree\nfour\nfive\nsix\nseven\n"
+
+	tests := []struct {
+		name    string
+		pattern string
+		want    []GrepLine
+		context int
+	}{
+		{
+			name:    "no match",
+			pattern: "eight",
+		},
+		{
+			name:    "matches without context",
+			pattern: "^t",
+			want: []GrepLine{
+				{Text: "two", Number: 2, Match: true},
+				{Text: "three", Number: 3, Match: true},
+			},
+		},
+		{
+			name:    "overlapping context",
+			pattern: "two|four",
+			context: 1,
+			want: []GrepLine{
+				{Text: "one", Number: 1},
+				{Text: "two", Number: 2, Match: true},
+				{Text: "three", Number: 3},
+				{Text: "four", Number: 4, Match: true},
+				{Text: "five", Number: 5},
+			},
+		},
+		{
+			name:    "context at the edges",
+			pattern: "one|seven",
+			context: 2,
+			want: []GrepLine{
+				{Text: "one", Number: 1, Match: true},
+				{Text: "two", Number: 2},
+				{Text: "three", Number: 3},
+				{Text: "five", Number: 5},
+				{Text: "six", Number: 6},
+				{Text: "seven", Number: 7, Match: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := grepLines(content, regexp.MustCompile(tt.pattern), tt.context)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestGrep(t *testing.T) {
+	archive := newTarGz(t, map[string]string{
+		"repo-main/main.go":      "package main\n\n// TODO: run\nfunc main() {}\n",
+		"repo-main/main_test.go": "package main\n\n// TODO: test\n",
+		"repo-main/README.md":    "# todo\n",
+		"repo-main/logo.png":     "\x89PNG\x00TODO",
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(archive)
+	}))
+	defer server.Close()
+
+	var results []GrepResult
+	err := New(&Config{
+		Url:     server.URL,
+		Dir:     "repo-main",
+		Exclude: []string{"_test.go"},
+	}, nil).Grep(regexp.MustCompile("TODO"), 0, func(r GrepResult) error {
+		results = append(results, r)
+		return nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []GrepResult{
+		{Path: "main.go", Lines: []GrepLine{{Text: "// TODO: run", Number: 3, Match: true}}},
+	}, results)
+}
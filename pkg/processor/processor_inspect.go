@@ -0,0 +1,211 @@
+package processor
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/iamhectorsosa/octomap/pkg/archive"
+	"github.com/iamhectorsosa/octomap/pkg/language"
+)
+
+const (
+	RuleInclude = "include"
+	RuleExclude = "exclude"
+	RuleLang    = "lang"
+)
+
+// Inspect previews the mapping without reading file contents. Only archive
+// headers are read, along with the root .gitignore file when it applies, so
+// languages are detected from file names and tokens are estimated from sizes.
+// The top largest selected files are listed.
+func (p *Processor) Inspect(top int) (*Inspection, error) {
+	if p.ch != nil {
+		defer close(p.ch)
+	}
+
+	reader, err := p.download()
+	if err != nil {
+		p.updateError(err)
+		return nil, err
+	}
+	defer reader.Close()
+
+	inspection, err := p.inspect(reader, top)
+	if err != nil {
+		p.updateError(err)
+		return nil, err
+	}
+
+	p.update(fmt.Sprintf("inspected: %d out of %d files selected", inspection.Selected, inspection.Files))
+	return inspection, nil
+}
+
+func (p *Processor) inspect(reader io.Reader, top int) (*Inspection, error) {
+	tarReader, err := archive.NewTarGzReader(reader)
+	if err != nil {
+		return nil, err
+	}
+	defer tarReader.Close()
+
+	inspection := &Inspection{
+		Repo:      p.config.Repo,
+		Url:       p.config.Url,
+		Dir:       p.config.Dir,
+		Largest:   []InspectedFile{},
+		Unmatched: []string{},
+	}
+
+	rules := make(map[string]*RuleMatch)
+	addRule := func(kind, rule string) {
+		// Rules given twice are listed once
+		for _, r := range inspection.Rules {
+			if r.Kind == kind && r.Rule == rule {
+				return
+			}
+		}
+		inspection.Rules = append(inspection.Rules, RuleMatch{
+			Kind:    kind,
+			Rule:    rule,
+			Matched: []string{},
+			Dropped: []string{},
+		})
+	}
+	for _, suffix := range p.config.Include {
+		addRule(RuleInclude, suffix)
+	}
+	for _, suffix := range p.config.Exclude {
+		addRule(RuleExclude, suffix)
+	}
+	for _, lang := range p.config.Langs {
+		addRule(RuleLang, lang)
+	}
+	for i := range inspection.Rules {
+		r := &inspection.Rules[i]
+		rules[r.Kind+" "+r.Rule] = r
+	}
+
+	var selected []InspectedFile
+	skip := func(relativePath, reason string) {
+		if inspection.Skipped == nil {
+			inspection.Skipped = make(map[string][]string)
+		}
+		inspection.Skipped[reason] = append(inspection.Skipped[reason], relativePath)
+	}
+	add := func(f InspectedFile) {
+		selected = append(selected, f)
+		inspection.Selected++
+		inspection.SelectedBytes += f.Size
+		inspection.Tokens += f.Tokens
+	}
+
+	// Files listed before .gitignore are checked against it once read, as
+	// when mapping
+	type pendingInspected struct {
+		file     InspectedFile
+		repoPath string
+	}
+	var pending []pendingInspected
+	for {
+		hdr, err := tarReader.ReadNext()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if hdr.IsDir {
+			inspection.Dirs++
+		}
+		if hdr.IsFile {
+			inspection.Files++
+			inspection.Bytes += hdr.Size
+		}
+		if p.config.Gitignore && !p.ignoreSettled {
+			if pastGitignore(repoPath(hdr.Name)) {
+				p.ignoreSettled = true
+			} else if hdr.IsFile && repoPath(hdr.Name) == gitignoreFile {
+				content, err := tarReader.ReadContent()
+				if err != nil {
+					return nil, err
+				}
+				p.ignoreRules, p.ignoreSettled = parseGitignore(content), true
+			}
+		}
+
+		if !hdr.IsFile || !p.withinDirs(hdr.Name) {
+			continue
+		}
+
+		relativePath := strings.TrimPrefix(hdr.Name, p.config.Dir+"/")
+		if p.ignoreSettled {
+			if _, ok := p.gitignored(repoPath(hdr.Name)); ok {
+				skip(relativePath, SkipGitignored)
+				continue
+			}
+		}
+
+		include, exclude, ok := p.matchRules(relativePath)
+		if include != "" {
+			r := rules[RuleInclude+" "+include]
+			r.Matched = append(r.Matched, relativePath)
+		}
+		if exclude != "" {
+			r := rules[RuleExclude+" "+exclude]
+			r.Dropped = append(r.Dropped, relativePath)
+			continue
+		}
+		if !ok || !p.selected(relativePath) {
+			inspection.Unmatched = append(inspection.Unmatched, relativePath)
+			continue
+		}
+		if p.config.MaxSize > 0 && hdr.Size > int64(p.config.MaxSize) {
+			skip(relativePath, SkipTooLarge)
+			continue
+		}
+
+		if len(p.config.Langs) > 0 {
+			lang := language.DetectName(relativePath)
+			if lang == "" {
+				lang = language.Other
+			}
+			if !slices.Contains(p.config.Langs, lang) {
+				inspection.Unmatched = append(inspection.Unmatched, relativePath)
+				continue
+			}
+			r := rules[RuleLang+" "+lang]
+			r.Matched = append(r.Matched, relativePath)
+		}
+
+		f := InspectedFile{Path: relativePath, Size: hdr.Size, Tokens: EstimateSizeTokens(hdr.Size)}
+		if p.config.Gitignore && !p.ignoreSettled {
+			pending = append(pending, pendingInspected{file: f, repoPath: repoPath(hdr.Name)})
+			continue
+		}
+		add(f)
+	}
+
+	for _, pf := range pending {
+		if _, ok := p.gitignored(pf.repoPath); ok {
+			skip(pf.file.Path, SkipGitignored)
+			continue
+		}
+		add(pf.file)
+	}
+
+	sort.SliceStable(selected, func(i, j int) bool {
+		if selected[i].Size != selected[j].Size {
+			return selected[i].Size > selected[j].Size
+		}
+		return selected[i].Path < selected[j].Path
+	})
+	if top >= 0 && len(selected) > top {
+		selected = selected[:top]
+	}
+	inspection.Largest = append(inspection.Largest, selected...)
+
+	return inspection, nil
+}
@@ -0,0 +1,153 @@
+package processor
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInspect(t *testing.T) {
+	archive := newTarGz(t, map[string]string{
+		"repo-main/main.go":      "package main\n\nfunc main() {}\n",
+		"repo-main/main_test.go": "package main\n",
+		"repo-main/README.md":    "# readme\n",
+		"repo-main/web/app.ts":   "export const app = 1\n",
+		"repo-main/go.sum":       "h1:\n",
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(archive)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name          string
+		config        *Config
+		top           int
+		wantSelected  int
+		wantLargest   []string
+		wantRules     []RuleMatch
+		wantUnmatched []string
+		wantSkipped   map[string][]string
+	}{
+		{
+			name:         "no rules",
+			config:       &Config{Dir: "repo-main"},
+			top:          2,
+			wantSelected: 5,
+			wantLargest:  []string{"main.go", "web/app.ts"},
+		},
+		{
+			name: "include and exclude",
+			config: &Config{
+				Dir:     "repo-main",
+				Include: []string{".go", ".ts"},
+				Exclude: []string{"_test.go"},
+			},
+			top:          10,
+			wantSelected: 2,
+			wantLargest:  []string{"main.go", "web/app.ts"},
+			wantRules: []RuleMatch{
+				{Kind: RuleInclude, Rule: ".go", Matched: []string{"main.go", "main_test.go"}, Dropped: []string{}},
+				{Kind: RuleInclude, Rule: ".ts", Matched: []string{"web/app.ts"}, Dropped: []string{}},
+				{Kind: RuleExclude, Rule: "_test.go", Matched: []string{}, Dropped: []string{"main_test.go"}},
+			},
+			wantUnmatched: []string{"README.md", "go.sum"},
+		},
+		{
+			name:         "languages",
+			config:       &Config{Dir: "repo-main", Langs: []string{"Markdown"}},
+			top:          10,
+			wantSelected: 1,
+			wantLargest:  []string{"README.md"},
+			wantRules: []RuleMatch{
+				{Kind: RuleLang, Rule: "Markdown", Matched: []string{"README.md"}, Dropped: []string{}},
+			},
+			wantUnmatched: []string{"go.sum", "main.go", "main_test.go", "web/app.ts"},
+		},
+		{
+			name:         "repeated rules",
+			config:       &Config{Dir: "repo-main", Include: []string{".go", ".go"}},
+			top:          10,
+			wantSelected: 2,
+			wantLargest:  []string{"main.go", "main_test.go"},
+			wantRules: []RuleMatch{
+				{Kind: RuleInclude, Rule: ".go", Matched: []string{"main.go", "main_test.go"}, Dropped: []string{}},
+			},
+			wantUnmatched: []string{"README.md", "go.sum", "web/app.ts"},
+		},
+		{
+			name:          "max size",
+			config:        &Config{Dir: "repo-main", MaxSize: 14},
+			top:           10,
+			wantSelected:  3,
+			wantLargest:   []string{"main_test.go", "README.md", "go.sum"},
+			wantUnmatched: []string{},
+			wantSkipped:   map[string][]string{SkipTooLarge: {"main.go", "web/app.ts"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.Url = server.URL
+			inspection, err := New(tt.config, nil).Inspect(tt.top)
+			require.NoError(t, err)
+
+			assert.Equal(t, 5, inspection.Files)
+			assert.Equal(t, tt.wantSelected, inspection.Selected)
+
+			var largest []string
+			for _, f := range inspection.Largest {
+				largest = append(largest, f.Path)
+				assert.Equal(t, EstimateSizeTokens(f.Size), f.Tokens)
+			}
+			assert.Equal(t, tt.wantLargest, largest)
+
+			// Archive entries are written in map order
+			for _, r := range inspection.Rules {
+				sort.Strings(r.Matched)
+				sort.Strings(r.Dropped)
+			}
+			assert.Equal(t, tt.wantRules, inspection.Rules)
+			assert.ElementsMatch(t, tt.wantUnmatched, inspection.Unmatched)
+			for reason := range inspection.Skipped {
+				sort.Strings(inspection.Skipped[reason])
+			}
+			assert.Equal(t, tt.wantSkipped, inspection.Skipped)
+		})
+	}
+}
+
+func TestInspectGitignore(t *testing.T) {
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	// Entries in Git tree order, with .github listed before .gitignore
+	for _, file := range [][2]string{
+		{"repo-main/.github/build.log", "ok\n"},
+		{"repo-main/.gitignore", "*.log\ndist/\n"},
+		{"repo-main/dist/app.js", "bundle\n"},
+		{"repo-main/main.go", "package main\n"},
+	} {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: file[0], Mode: 0644, Size: int64(len(file[1]))}))
+		tw.Write([]byte(file[1]))
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gw.Close())
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(buf.Bytes())
+	}))
+	defer server.Close()
+
+	inspection, err := New(&Config{Url: server.URL, Dir: "repo-main", Gitignore: true}, nil).Inspect(10)
+	require.NoError(t, err)
+	assert.Equal(t, 2, inspection.Selected)
+	assert.Equal(t, map[string][]string{SkipGitignored: {"dist/app.js", ".github/build.log"}}, inspection.Skipped)
+}
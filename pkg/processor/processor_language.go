@@ -0,0 +1,82 @@
+package processor
+
+import (
+	"slices"
+	"sort"
+
+	"github.com/iamhectorsosa/octomap/pkg/language"
+)
+
+// detectLanguage is the pipeline transform that detects the language of files
+// not already detected while filtering.
+func (p *Processor) detectLanguage(f *File) error {
+	if f.Language == "" {
+		f.Language = language.Detect(f.Path, f.Content)
+	}
+	return nil
+}
+
+// matchesLangs reports whether a file is in one of the configured languages.
+// Content is only read when the file name is not conclusive.
+func (p *Processor) matchesLangs(f *File, readContent func() (string, error)) (bool, error) {
+	if len(p.config.Langs) == 0 {
+		return true, nil
+	}
+
+	lang := language.DetectName(f.Path)
+	if lang == "" && p.config.TreeOnly {
+		lang = language.Other
+	}
+	if lang == "" {
+		content, err := readContent()
+		if err != nil {
+			return false, err
+		}
+		f.Content = content
+		lang = language.Detect(f.Path, content)
+	}
+	f.Language = lang
+
+	return slices.Contains(p.config.Langs, lang), nil
+}
+
+// count adds an inserted file to the language statistics.
+func (p *Processor) count(f *File) {
+	stats, ok := p.languages[f.Language]
+	if !ok {
+		stats = &LanguageStats{Language: f.Language}
+		p.languages[f.Language] = stats
+	}
+
+	stats.Files++
+	if p.config.TreeOnly {
+		// Only sizes are known without contents
+		stats.Bytes += int(f.Size)
+		stats.Tokens += EstimateSizeTokens(f.Size)
+		return
+	}
+	stats.Bytes += len(f.Content)
+	stats.Lines += CountLines(f.Content)
+	stats.Tokens += EstimateTokens(f.Content)
+}
+
+// Stats returns the statistics of the mapped files.
+func (p *Processor) Stats() Stats {
+	stats := Stats{Skipped: p.skipCounts()}
+	for _, l := range p.languages {
+		stats.Languages = append(stats.Languages, *l)
+		stats.Files += l.Files
+		stats.Bytes += l.Bytes
+		stats.Lines += l.Lines
+		stats.Tokens += l.Tokens
+	}
+
+	sort.Slice(stats.Languages, func(i, j int) bool {
+		a, b := stats.Languages[i], stats.Languages[j]
+		if a.Bytes != b.Bytes {
+			return a.Bytes > b.Bytes
+		}
+		return a.Language < b.Language
+	})
+	return stats
+}
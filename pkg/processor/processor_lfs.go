@@ -0,0 +1,228 @@
+package processor
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/iamhectorsosa/octomap/pkg/archive"
+)
+
+const (
+	LFSPointer = "pointer"
+	LFSSkip    = "skip"
+	LFSResolve = "resolve"
+
+	githubLFS = "https://github.com/%s/%s.git/info/lfs"
+
+	lfsMediaType = "application/vnd.git-lfs+json"
+
+	errLFSBatch    = "lfs batch error: %v"
+	errLFSStatus   = "lfs unexpected status code: %d"
+	errLFSObject   = "lfs object error: %d %s"
+	errLFSNoAction = "lfs object has no download action"
+	errLFSChecksum = "lfs object does not match its oid"
+)
+
+var lfsModes = []string{LFSPointer, LFSSkip, LFSResolve}
+
+// lfsClient bounds the batch request and object downloads, which are not
+// tied to the archive download.
+var lfsClient = &http.Client{Timeout: 5 * time.Minute}
+
+type lfsBatchRequest struct {
+	Operation string      `json:"operation"`
+	Transfers []string    `json:"transfers"`
+	Objects   []lfsObject `json:"objects"`
+}
+
+type lfsObject struct {
+	Actions *struct {
+		Download *struct {
+			Header map[string]string `json:"header"`
+			Href   string            `json:"href"`
+		} `json:"download"`
+	} `json:"actions,omitempty"`
+	Error *struct {
+		Message string `json:"message"`
+		Code    int    `json:"code"`
+	} `json:"error,omitempty"`
+	Oid  string `json:"oid"`
+	Size int64  `json:"size"`
+}
+
+type lfsBatchResponse struct {
+	Objects []lfsObject `json:"objects"`
+}
+
+// handleLFS records a Git LFS pointer and applies the LFS mode, reporting
+// whether the file is still mapped. Pointers to resolve are kept on the file,
+// unless their object is larger than the size limit. Tree-only mapping
+// without a content filter maps the object size without fetching it.
+func (p *Processor) handleLFS(f *File, pointer *archive.LFSPointer) bool {
+	p.lfs = append(p.lfs, LFSFile{Path: f.Path, Oid: pointer.Oid, Size: pointer.Size})
+
+	switch p.config.LFS {
+	case LFSSkip:
+		p.skip(f.Path, SkipLFS, "")
+		return false
+	case LFSResolve:
+		if p.config.MaxSize > 0 && pointer.Size > int64(p.config.MaxSize) {
+			p.skip(f.Path, SkipTooLarge, "")
+			return false
+		}
+		if p.config.TreeOnly && p.config.Contains == nil {
+			f.Size = pointer.Size
+			return true
+		}
+		f.pointer = pointer
+	}
+	return true
+}
+
+// submit passes a file to emit, holding back files whose LFS object is
+// resolved once the archive is read.
+func (p *Processor) submit(f *File, emit func(f *File) bool) bool {
+	if f.pointer != nil {
+		p.lfsPending = append(p.lfsPending, f)
+		return true
+	}
+	return emit(f)
+}
+
+// resolveLFS fetches the objects of the held back LFS pointers with a single
+// batch request and passes the files to emit with the object in place of the
+// pointer, until it returns false. Objects that cannot be fetched are skipped.
+func (p *Processor) resolveLFS(emit func(f *File) bool) {
+	pending := p.lfsPending
+	p.lfsPending = nil
+	if len(pending) == 0 {
+		return
+	}
+
+	pointers := make([]*archive.LFSPointer, len(pending))
+	for i, f := range pending {
+		pointers[i] = f.pointer
+	}
+	objects, batchErr := p.batchLFS(pointers)
+
+	for _, f := range pending {
+		pointer := f.pointer
+		f.pointer = nil
+
+		err := batchErr
+		var content string
+		if err == nil {
+			content, err = downloadLFS(objects[pointer.Oid], pointer)
+		}
+		if err != nil {
+			p.update(fmt.Sprintf("unresolved: %s (%v)", f.Path, err))
+			p.skip(f.Path, SkipLFSUnresolved, "")
+			continue
+		}
+
+		for i := range p.lfs {
+			if p.lfs[i].Path == f.Path {
+				p.lfs[i].Resolved = true
+			}
+		}
+		f.Content, f.Size = content, pointer.Size
+		if !p.filterContent(f) {
+			continue
+		}
+		if p.config.TreeOnly {
+			f.Content = ""
+		}
+		if !emit(f) {
+			return
+		}
+	}
+}
+
+// batchLFS requests the download actions of LFS objects through the LFS
+// batch API of the repository, returning the objects by oid.
+func (p *Processor) batchLFS(pointers []*archive.LFSPointer) (map[string]lfsObject, error) {
+	request := lfsBatchRequest{Operation: "download", Transfers: []string{"basic"}}
+	seen := make(map[string]bool)
+	for _, pointer := range pointers {
+		if !seen[pointer.Oid] {
+			seen[pointer.Oid] = true
+			request.Objects = append(request.Objects, lfsObject{Oid: pointer.Oid, Size: pointer.Size})
+		}
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, p.config.LFSUrl+"/objects/batch", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", lfsMediaType)
+	req.Header.Set("Content-Type", lfsMediaType)
+
+	resp, err := lfsClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf(errLFSBatch, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf(errLFSStatus, resp.StatusCode)
+	}
+
+	var batch lfsBatchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&batch); err != nil {
+		return nil, fmt.Errorf(errLFSBatch, err)
+	}
+	objects := make(map[string]lfsObject, len(batch.Objects))
+	for _, object := range batch.Objects {
+		objects[object.Oid] = object
+	}
+	return objects, nil
+}
+
+// downloadLFS downloads an LFS object through its download action and checks
+// it against its oid.
+func downloadLFS(object lfsObject, pointer *archive.LFSPointer) (string, error) {
+	if object.Error != nil {
+		return "", fmt.Errorf(errLFSObject, object.Error.Code, object.Error.Message)
+	}
+	if object.Actions == nil || object.Actions.Download == nil {
+		return "", fmt.Errorf(errLFSNoAction)
+	}
+
+	download := object.Actions.Download
+	req, err := http.NewRequest(http.MethodGet, download.Href, nil)
+	if err != nil {
+		return "", err
+	}
+	for key, value := range download.Header {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := lfsClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("request error: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf(errLFSStatus, resp.StatusCode)
+	}
+
+	// Read one byte past the expected size to catch oversized objects
+	content, err := io.ReadAll(io.LimitReader(resp.Body, pointer.Size+1))
+	if err != nil {
+		return "", fmt.Errorf("request error: %v", err)
+	}
+	sum := sha256.Sum256(content)
+	if hex.EncodeToString(sum[:]) != pointer.Oid {
+		return "", fmt.Errorf(errLFSChecksum)
+	}
+	return string(content), nil
+}
@@ -0,0 +1,141 @@
+package processor
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func lfsPointer(content string) (pointer, oid string) {
+	sum := sha256.Sum256([]byte(content))
+	oid = hex.EncodeToString(sum[:])
+	return fmt.Sprintf("version https://git-lfs.github.com/spec/v1\noid sha256:%s\nsize %d\n", oid, len(content)), oid
+}
+
+func TestProcessLFS(t *testing.T) {
+	data := "id,name\n1,octomap\n"
+	dataPointer, dataOid := lfsPointer(data)
+	missingPointer, missingOid := lfsPointer("missing")
+	largePointer, largeOid := lfsPointer(strings.Repeat("0123456789", 30))
+
+	archive := newTarGz(t, map[string]string{
+		"repo-main/main.go":        "package main\n",
+		"repo-main/data/users.csv": dataPointer,
+		"repo-main/data/gone.csv":  missingPointer,
+		"repo-main/data/large.csv": largePointer,
+	})
+
+	var server *httptest.Server
+	var batches int
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/archive":
+			w.Write(archive)
+		case "/lfs/objects/batch":
+			batches++
+			assert.Equal(t, lfsMediaType, r.Header.Get("Accept"))
+			var req lfsBatchRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			var objects []string
+			for _, object := range req.Objects {
+				if object.Oid == dataOid {
+					objects = append(objects, fmt.Sprintf(`{"oid":%q,"size":%d,"actions":{"download":{"href":"%s/objects/%s","header":{"Authorization":"token"}}}}`,
+						object.Oid, object.Size, server.URL, object.Oid))
+					continue
+				}
+				objects = append(objects, fmt.Sprintf(`{"oid":%q,"size":%d,"error":{"code":404,"message":"Object does not exist"}}`, object.Oid, object.Size))
+			}
+			fmt.Fprintf(w, `{"objects":[%s]}`, strings.Join(objects, ","))
+		case "/objects/" + dataOid:
+			assert.Equal(t, "token", r.Header.Get("Authorization"))
+			fmt.Fprint(w, data)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	newProcessor := func(mode string, treeOnly bool) *Processor {
+		return New(&Config{
+			Repo:        "test-repo",
+			Url:         server.URL + "/archive",
+			LFSUrl:      server.URL + "/lfs",
+			Dir:         "repo-main",
+			Stdout:      true,
+			LFS:         mode,
+			MaxSize:     200,
+			TreeOnly:    treeOnly,
+			ListSkipped: true,
+		}, nil)
+	}
+
+	t.Run("pointer", func(t *testing.T) {
+		p := newProcessor(LFSPointer, false)
+		got, err := p.Process()
+		require.NoError(t, err)
+		assert.Equal(t, dataPointer, got["data"].(map[string]interface{})["users.csv"])
+		assert.Equal(t, []LFSFile{
+			{Path: "data/gone.csv", Oid: missingOid, Size: 7},
+			{Path: "data/large.csv", Oid: largeOid, Size: 300},
+			{Path: "data/users.csv", Oid: dataOid, Size: int64(len(data))},
+		}, p.Manifest().LFS)
+	})
+
+	t.Run("skip", func(t *testing.T) {
+		p := newProcessor(LFSSkip, false)
+		got, err := p.Process()
+		require.NoError(t, err)
+		assert.Equal(t, RepositoryData{"main.go": "package main\n"}, got)
+		assert.Equal(t, map[string]int{SkipLFS: 3}, p.Stats().Skipped)
+	})
+
+	t.Run("resolve", func(t *testing.T) {
+		batches = 0
+		p := newProcessor(LFSResolve, false)
+		got, err := p.Process()
+		require.NoError(t, err)
+		assert.Equal(t, RepositoryData{
+			"main.go": "package main\n",
+			"data": map[string]interface{}{
+				"users.csv": data,
+			},
+		}, got)
+		assert.Equal(t, []Skip{
+			{Path: "data/gone.csv", Reason: SkipLFSUnresolved},
+			{Path: "data/large.csv", Reason: SkipTooLarge},
+		}, p.Skipped())
+		assert.Equal(t, LFSFile{Path: "data/users.csv", Oid: dataOid, Size: int64(len(data)), Resolved: true}, p.Manifest().LFS[2])
+		assert.Equal(t, 1, batches)
+	})
+
+	t.Run("tree-only", func(t *testing.T) {
+		p := newProcessor(LFSPointer, true)
+		got, err := p.Process()
+		require.NoError(t, err)
+		assert.Equal(t, int64(len(dataPointer)), got["data"].(map[string]interface{})["users.csv"])
+		assert.Len(t, p.Manifest().LFS, 3)
+	})
+
+	t.Run("tree-only resolve", func(t *testing.T) {
+		batches = 0
+		p := newProcessor(LFSResolve, true)
+		got, err := p.Process()
+		require.NoError(t, err)
+		assert.Equal(t, RepositoryData{
+			"main.go": int64(13),
+			"data": map[string]interface{}{
+				"gone.csv":  int64(7),
+				"users.csv": int64(len(data)),
+			},
+		}, got)
+		assert.Equal(t, 0, batches)
+	})
+}
@@ -0,0 +1,172 @@
+package processor
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/iamhectorsosa/octomap/pkg/archive"
+	"github.com/iamhectorsosa/octomap/pkg/language"
+)
+
+// maxLinkHops bounds how many links pointing to links are followed, as the
+// kernel does to break cycles.
+const maxLinkHops = 40
+
+// readLink records a symbolic or hard link, refusing links whose target
+// escapes the repository root.
+func (p *Processor) readLink(hdr *archive.ArchiveHeader, relativePath string) {
+	target, ok := linkTarget(hdr)
+	if !ok {
+		p.skip(relativePath, SkipLinkEscape, "")
+		p.update(fmt.Sprintf("refused: %s -> %s", relativePath, hdr.LinkTarget))
+		return
+	}
+
+	p.links = append(p.links, Link{Path: relativePath, Type: hdr.LinkType, Target: hdr.LinkTarget})
+	if p.config.FollowSymlinks {
+		if p.linkTargets == nil {
+			p.linkTargets = make(map[string]string)
+		}
+		p.linkTargets[relativePath] = target
+	}
+}
+
+// linkTarget returns the target of a link relative to the repository root,
+// and false when it is absolute or escapes the root.
+func linkTarget(hdr *archive.ArchiveHeader) (string, bool) {
+	if hdr.LinkType == archive.LinkHard {
+		// Hard links name another entry of the archive
+		root, _, _ := strings.Cut(hdr.Name, "/")
+		target, ok := strings.CutPrefix(path.Clean(hdr.LinkTarget), root+"/")
+		if !ok {
+			return "", false
+		}
+		return target, true
+	}
+
+	if path.IsAbs(hdr.LinkTarget) {
+		return "", false
+	}
+	target := path.Join(path.Dir(repoPath(hdr.Name)), hdr.LinkTarget)
+	if target == ".." || strings.HasPrefix(target, "../") {
+		return "", false
+	}
+	return target, true
+}
+
+// followLinks maps the content of the target of every link at the path of
+// the link. Targets are looked up among the mapped files, so the include,
+// exclude, language and path filters apply to the files a link reaches
+// rather than to its name, and links to files left out of the report are
+// listed but not followed.
+func (p *Processor) followLinks() error {
+	if !p.config.FollowSymlinks {
+		return nil
+	}
+
+	for i := range p.links {
+		link := &p.links[i]
+		target, ok := p.linkTargets[link.Path]
+		if !ok {
+			continue
+		}
+
+		targetPath, node, ok := p.resolveLink(target)
+		if !ok {
+			p.update(fmt.Sprintf("unfollowed: %s -> %s", link.Path, link.Target))
+			continue
+		}
+		if err := p.insertLink(link.Path, targetPath, node); err != nil {
+			return err
+		}
+		link.Followed = true
+	}
+	return nil
+}
+
+// resolveLink follows a target through links pointing to links and returns
+// its path and node in the report.
+func (p *Processor) resolveLink(target string) (string, interface{}, bool) {
+	for hops := 0; hops < maxLinkHops; hops++ {
+		targetPath, ok := p.dataPath(target)
+		if !ok {
+			return "", nil, false
+		}
+		if next, ok := p.linkTargets[targetPath]; ok {
+			target = next
+			continue
+		}
+
+		var node interface{} = map[string]interface{}(p.data)
+		for _, part := range strings.Split(targetPath, "/") {
+			dir, ok := node.(map[string]interface{})
+			if !ok {
+				return "", nil, false
+			}
+			if node, ok = dir[part]; !ok {
+				return "", nil, false
+			}
+		}
+		return targetPath, node, true
+	}
+	return "", nil, false
+}
+
+// dataPath returns a path relative to the repository root relative to the
+// root of the report, and false when it is outside of it or the root itself.
+func (p *Processor) dataPath(repoRelative string) (string, bool) {
+	base := ""
+	if len(p.config.Dirs) == 0 {
+		_, base, _ = strings.Cut(p.config.Dir, "/")
+	}
+	if base == "" {
+		return repoRelative, repoRelative != "."
+	}
+	rest, ok := subdir(repoRelative, base)
+	return rest, ok && rest != ""
+}
+
+// insertLink maps the files of a target node at the path of a link, a single
+// file for a file target and every file below it for a directory target.
+func (p *Processor) insertLink(linkPath, targetPath string, node interface{}) error {
+	type leaf struct {
+		path  string
+		value interface{}
+	}
+
+	// Files are collected before inserting, as a link may point to a
+	// directory containing it
+	var leaves []leaf
+	var walk func(name string, node interface{})
+	walk = func(name string, node interface{}) {
+		dir, ok := node.(map[string]interface{})
+		if !ok {
+			leaves = append(leaves, leaf{path: name, value: node})
+			return
+		}
+		for child, value := range dir {
+			walk(name+"/"+child, value)
+		}
+	}
+	walk("", node)
+	sort.Slice(leaves, func(i, j int) bool {
+		return leaves[i].path < leaves[j].path
+	})
+
+	for _, l := range leaves {
+		f := &File{Path: linkPath + l.path}
+		switch value := l.value.(type) {
+		case string:
+			f.Content, f.Size = value, int64(len(value))
+		case int64:
+			f.Size = value
+		}
+		f.Language = language.Detect(targetPath+l.path, f.Content)
+		if err := p.insert(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package processor
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/iamhectorsosa/octomap/pkg/archive"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinkTarget(t *testing.T) {
+	tests := []struct {
+		hdr    archive.ArchiveHeader
+		want   string
+		wantOk bool
+	}{
+		{hdr: archive.ArchiveHeader{Name: "repo-main/README.md", LinkTarget: "docs/guide.md"}, want: "docs/guide.md", wantOk: true},
+		{hdr: archive.ArchiveHeader{Name: "repo-main/docs/api.md", LinkTarget: "../README.md"}, want: "README.md", wantOk: true},
+		{hdr: archive.ArchiveHeader{Name: "repo-main/docs/self", LinkTarget: "."}, want: "docs", wantOk: true},
+		{hdr: archive.ArchiveHeader{Name: "repo-main/docs/up", LinkTarget: "../../secret"}, wantOk: false},
+		{hdr: archive.ArchiveHeader{Name: "repo-main/passwd", LinkTarget: "/etc/passwd"}, wantOk: false},
+		{hdr: archive.ArchiveHeader{Name: "repo-main/copy.go", LinkType: archive.LinkHard, LinkTarget: "repo-main/main.go"}, want: "main.go", wantOk: true},
+		{hdr: archive.ArchiveHeader{Name: "repo-main/copy.go", LinkType: archive.LinkHard, LinkTarget: "other/main.go"}, wantOk: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.hdr.Name+" -> "+tt.hdr.LinkTarget, func(t *testing.T) {
+			if tt.hdr.LinkType == "" {
+				tt.hdr.LinkType = archive.LinkSymbolic
+			}
+			got, ok := linkTarget(&tt.hdr)
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestProcessLinks(t *testing.T) {
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	for _, hdr := range []*tar.Header{
+		{Typeflag: tar.TypeReg, Name: "repo-main/main.go", Size: 13},
+		{Typeflag: tar.TypeReg, Name: "repo-main/docs/guide.md", Size: 8},
+		{Typeflag: tar.TypeSymlink, Name: "repo-main/README.md", Linkname: "docs/guide.md"},
+		{Typeflag: tar.TypeSymlink, Name: "repo-main/manual", Linkname: "docs"},
+		{Typeflag: tar.TypeSymlink, Name: "repo-main/intro.md", Linkname: "README.md"},
+		{Typeflag: tar.TypeSymlink, Name: "repo-main/missing.go", Linkname: "gone.go"},
+		{Typeflag: tar.TypeSymlink, Name: "repo-main/docs/escape", Linkname: "../../secret"},
+		{Typeflag: tar.TypeLink, Name: "repo-main/copy.go", Linkname: "repo-main/main.go"},
+		{Typeflag: tar.TypeFifo, Name: "repo-main/pipe"},
+	} {
+		hdr.Mode = 0644
+		require.NoError(t, tw.WriteHeader(hdr))
+		switch hdr.Name {
+		case "repo-main/main.go":
+			tw.Write([]byte("package main\n"))
+		case "repo-main/docs/guide.md":
+			tw.Write([]byte("# Guide\n"))
+		}
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gw.Close())
+	archive := buf.Bytes()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(archive)
+	}))
+	defer server.Close()
+
+	newProcessor := func(follow bool, include ...string) *Processor {
+		return New(&Config{
+			Repo:           "test-repo",
+			Url:            server.URL,
+			Dir:            "repo-main",
+			Stdout:         true,
+			ListSkipped:    true,
+			FollowSymlinks: follow,
+			Include:        include,
+		}, nil)
+	}
+
+	t.Run("metadata", func(t *testing.T) {
+		p := newProcessor(false)
+		got, err := p.Process()
+		require.NoError(t, err)
+		assert.Equal(t, RepositoryData{
+			"main.go": "package main\n",
+			"docs":    map[string]interface{}{"guide.md": "# Guide\n"},
+		}, got)
+
+		manifest := p.Manifest()
+		assert.Equal(t, []Link{
+			{Path: "README.md", Type: "symlink", Target: "docs/guide.md"},
+			{Path: "copy.go", Type: "hardlink", Target: "repo-main/main.go"},
+			{Path: "intro.md", Type: "symlink", Target: "README.md"},
+			{Path: "manual", Type: "symlink", Target: "docs"},
+			{Path: "missing.go", Type: "symlink", Target: "gone.go"},
+		}, manifest.Links)
+		assert.Equal(t, []Skip{
+			{Path: "docs/escape", Reason: SkipLinkEscape},
+			{Path: "pipe", Reason: SkipSpecial},
+		}, manifest.Skipped)
+	})
+
+	t.Run("follow", func(t *testing.T) {
+		p := newProcessor(true)
+		got, err := p.Process()
+		require.NoError(t, err)
+		assert.Equal(t, RepositoryData{
+			"main.go":   "package main\n",
+			"copy.go":   "package main\n",
+			"README.md": "# Guide\n",
+			"intro.md":  "# Guide\n",
+			"docs":      map[string]interface{}{"guide.md": "# Guide\n"},
+			"manual":    map[string]interface{}{"guide.md": "# Guide\n"},
+		}, got)
+
+		followed := map[string]bool{}
+		for _, link := range p.Manifest().Links {
+			followed[link.Path] = link.Followed
+		}
+		assert.Equal(t, map[string]bool{
+			"README.md":  true,
+			"copy.go":    true,
+			"intro.md":   true,
+			"manual":     true,
+			"missing.go": false,
+		}, followed)
+		assert.Equal(t, 6, p.Stats().Files)
+	})
+
+	t.Run("follow filtered", func(t *testing.T) {
+		p := newProcessor(true, ".md")
+		got, err := p.Process()
+		require.NoError(t, err)
+		assert.Equal(t, RepositoryData{
+			"README.md": "# Guide\n",
+			"intro.md":  "# Guide\n",
+			"docs":      map[string]interface{}{"guide.md": "# Guide\n"},
+			"manual":    map[string]interface{}{"guide.md": "# Guide\n"},
+		}, got)
+
+		followed := map[string]bool{}
+		for _, link := range p.Manifest().Links {
+			followed[link.Path] = link.Followed
+		}
+		assert.False(t, followed["copy.go"])
+		assert.True(t, followed["manual"])
+	})
+}
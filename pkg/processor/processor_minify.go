@@ -0,0 +1,324 @@
+package processor
+
+import (
+	"bytes"
+	"go/scanner"
+	"go/token"
+	"path"
+	"strings"
+)
+
+type commentStyle int
+
+const (
+	noComments commentStyle = iota
+	goComments
+	cComments
+	jsComments
+	pythonComments
+	shellComments
+)
+
+var commentStyles = map[string]commentStyle{
+	".go":    goComments,
+	".c":     cComments,
+	".h":     cComments,
+	".cc":    cComments,
+	".cpp":   cComments,
+	".hpp":   cComments,
+	".cs":    cComments,
+	".java":  cComments,
+	".kt":    cComments,
+	".scala": cComments,
+	".swift": cComments,
+	".rs":    cComments,
+	".js":    jsComments,
+	".jsx":   jsComments,
+	".mjs":   jsComments,
+	".ts":    jsComments,
+	".tsx":   jsComments,
+	".proto": cComments,
+	".py":    pythonComments,
+	".sh":    shellComments,
+	".bash":  shellComments,
+	".zsh":   shellComments,
+	".yml":   shellComments,
+	".yaml":  shellComments,
+}
+
+// minify is the pipeline transform that strips comments, license headers and
+// redundant blank lines from files in a supported language.
+func (p *Processor) minify(f *File) error {
+	style, ok := commentStyles[path.Ext(f.Path)]
+	if !ok {
+		return nil
+	}
+
+	var content string
+	switch style {
+	case goComments:
+		content, ok = stripGoComments(f.Content)
+		if !ok {
+			return nil
+		}
+	case cComments:
+		content = stripCComments(f.Content, false)
+	case jsComments:
+		content = stripCComments(f.Content, true)
+	case pythonComments:
+		content = stripHashComments(f.Content, false)
+	case shellComments:
+		content = stripHashComments(f.Content, true)
+	}
+	content = collapseBlankLines(content)
+
+	f.originalTokens = EstimateTokens(f.Content)
+	f.savedTokens = f.originalTokens - EstimateTokens(content)
+	f.Content = content
+	return nil
+}
+
+// stripGoComments removes comments using the Go scanner, keeping compiler
+// directives such as //go:build. It reports false for sources that do not
+// scan cleanly.
+func stripGoComments(src string) (string, bool) {
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(src))
+
+	var s scanner.Scanner
+	failed := false
+	s.Init(file, []byte(src), func(token.Position, string) { failed = true }, scanner.ScanComments)
+
+	var out []byte
+	last := 0
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if tok != token.COMMENT || isGoDirective(lit) {
+			continue
+		}
+		start := file.Offset(pos)
+		out = append(out, src[last:start]...)
+		out, last = removeComment(out, src, start, start+len(lit))
+	}
+	if failed {
+		return "", false
+	}
+	out = append(out, src[last:]...)
+	return string(out), true
+}
+
+func isGoDirective(comment string) bool {
+	return strings.HasPrefix(comment, "//go:") || strings.HasPrefix(comment, "// +build") ||
+		strings.HasPrefix(comment, "//export ") || strings.HasPrefix(comment, "//line ")
+}
+
+// removeComment drops src[start:end] and returns the offset to continue from.
+// A comment on a line of its own is removed along with the line; a block
+// comment elsewhere is replaced so the tokens around it stay apart.
+func removeComment(out []byte, src string, start, end int) ([]byte, int) {
+	lineStart := bytes.LastIndexByte(out, '\n') + 1
+	ownLine := len(bytes.TrimLeft(out[lineStart:], " \t")) == 0
+	rest := strings.TrimLeft(src[end:], " \t\r")
+	if ownLine && (rest == "" || rest[0] == '\n') {
+		out = out[:lineStart]
+		end = len(src) - len(rest)
+		if rest != "" {
+			end++
+		}
+		return out, end
+	}
+
+	comment := src[start:end]
+	if strings.HasPrefix(comment, "/*") {
+		if strings.Contains(comment, "\n") {
+			out = append(out, '\n')
+		} else {
+			out = append(out, ' ')
+		}
+	}
+	return out, end
+}
+
+// stripCComments removes // and /* */ comments outside of string, character
+// and template literals, and with regex outside of JavaScript regular
+// expression literals.
+func stripCComments(src string, regex bool) string {
+	var out []byte
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '"' || c == '\'' || c == '`':
+			end := skipQuoted(src, i, string(c), true)
+			out = append(out, src[i:end]...)
+			i = end
+		case regex && c == '/' && !strings.HasPrefix(src[i:], "//") && !strings.HasPrefix(src[i:], "/*") && regexAllowed(out):
+			end := skipRegex(src, i)
+			out = append(out, src[i:end]...)
+			i = end
+		case strings.HasPrefix(src[i:], "//"):
+			out, i = removeComment(out, src, i, lineEnd(src, i))
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return string(out)
+			}
+			out, i = removeComment(out, src, i, i+2+end+2)
+		default:
+			out = append(out, c)
+			i++
+		}
+	}
+	return string(out)
+}
+
+// jsRegexKeywords are the keywords after which a slash starts a regular
+// expression literal rather than a division.
+var jsRegexKeywords = map[string]bool{
+	"return": true, "typeof": true, "instanceof": true, "in": true, "of": true, "new": true,
+	"delete": true, "void": true, "throw": true, "case": true, "do": true, "else": true,
+	"yield": true, "await": true,
+}
+
+// regexAllowed reports whether a slash following out starts a regular
+// expression literal, that is whether it follows an operator, punctuation
+// or a keyword rather than an operand. As in most tools short of a parser,
+// a slash after ), ] or } is taken as a division.
+func regexAllowed(out []byte) bool {
+	out = bytes.TrimRight(out, " \t\r\n")
+	if len(out) == 0 {
+		return true
+	}
+	if strings.IndexByte("(,=:[!&|?{};+-*%<>~^", out[len(out)-1]) >= 0 {
+		return true
+	}
+	start := len(out)
+	for start > 0 && isIdentByte(out[start-1]) {
+		start--
+	}
+	return jsRegexKeywords[string(out[start:])]
+}
+
+// skipRegex returns the offset after the regular expression literal at
+// start, where a slash within a character class does not end it. A line
+// ending first means the slash was a division, and start+1 is returned.
+func skipRegex(src string, start int) int {
+	inClass := false
+	for i := start + 1; i < len(src); i++ {
+		switch src[i] {
+		case '\\':
+			i++
+		case '[':
+			inClass = true
+		case ']':
+			inClass = false
+		case '/':
+			if !inClass {
+				return i + 1
+			}
+		case '\n':
+			return start + 1
+		}
+	}
+	return start + 1
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c == '$' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// stripHashComments removes # comments outside of quoted strings. With
+// wordStart, as in shell and YAML, # only starts a comment at the beginning
+// of a line or after whitespace. A leading shebang line is kept.
+func stripHashComments(src string, wordStart bool) string {
+	var out []byte
+	i := 0
+	if strings.HasPrefix(src, "#!") {
+		i = lineEnd(src, 0)
+		out = append(out, src[:i]...)
+	}
+
+	for i < len(src) {
+		c := src[i]
+		switch {
+		case !wordStart && (strings.HasPrefix(src[i:], `"""`) || strings.HasPrefix(src[i:], `'''`)):
+			end := skipQuoted(src, i, src[i:i+3], true)
+			out = append(out, src[i:end]...)
+			i = end
+		case c == '"' || c == '\'':
+			// Shell and YAML single quotes have no backslash escapes
+			end := skipQuoted(src, i, string(c), !wordStart || c == '"')
+			out = append(out, src[i:end]...)
+			i = end
+		case c == '#' && (!wordStart || i == 0 || isSpace(src[i-1])):
+			out, i = removeComment(out, src, i, lineEnd(src, i))
+		default:
+			out = append(out, c)
+			i++
+		}
+	}
+	return string(out)
+}
+
+// lineEnd returns the offset of the newline ending the line at i, or the end
+// of src.
+func lineEnd(src string, i int) int {
+	end := strings.IndexByte(src[i:], '\n')
+	if end < 0 {
+		return len(src)
+	}
+	return i + end
+}
+
+// skipQuoted returns the offset just past the literal opened by quote at
+// start, honoring backslash escapes when asked. Single and double quoted
+// literals end at a newline if unterminated.
+func skipQuoted(src string, start int, quote string, escapes bool) int {
+	i := start + len(quote)
+	for i < len(src) {
+		switch {
+		case escapes && src[i] == '\\':
+			i += 2
+		case strings.HasPrefix(src[i:], quote):
+			return i + len(quote)
+		case src[i] == '\n' && len(quote) == 1 && quote != "`":
+			return i
+		default:
+			i++
+		}
+	}
+	return len(src)
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// collapseBlankLines trims trailing whitespace, drops leading and trailing
+// blank lines and keeps at most one blank line in a row.
+func collapseBlankLines(src string) string {
+	lines := strings.Split(src, "\n")
+	kept := lines[:0]
+	blank := true
+	for _, line := range lines {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" {
+			if blank {
+				continue
+			}
+			blank = true
+		} else {
+			blank = false
+		}
+		kept = append(kept, line)
+	}
+	for len(kept) > 0 && kept[len(kept)-1] == "" {
+		kept = kept[:len(kept)-1]
+	}
+	if len(kept) == 0 {
+		return ""
+	}
+	return strings.Join(kept, "\n") + "\n"
+}
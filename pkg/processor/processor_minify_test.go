@@ -0,0 +1,146 @@
+package processor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMinify(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		content string
+		want    string
+	}{
+		{
+			name: "Go file",
+			path: "main.go",
+			content: `// Copyright 2024 Someone. All rights reserved.
+// Use of this source code is governed by a MIT license.
+
+//go:build linux
+
+// Package main is the entry point.
+package main
+
+import "fmt"
+
+
+func main() {
+	// say hello
+	fmt.Println("// not a comment") /* trailing */
+	x := 1/*inline*/+2
+	_ = x
+}
+`,
+			want: `//go:build linux
+
+package main
+
+import "fmt"
+
+func main() {
+	fmt.Println("// not a comment")
+	x := 1 +2
+	_ = x
+}
+`,
+		},
+		{
+			name: "TypeScript file",
+			path: "src/app.ts",
+			content: `/**
+ * License header
+ */
+const url = "http://example.com"; // endpoint
+const tpl = ` + "`/* kept */`" + `;
+/* block
+   comment */ const n = 1;
+`,
+			want: `const url = "http://example.com";
+const tpl = ` + "`/* kept */`" + `;
+
+ const n = 1;
+`,
+		},
+		{
+			name: "JavaScript regular expressions",
+			path: "src/url.js",
+			content: `const isUrl = (u) => /https?:\/\//.test(u); // scheme
+if (/[/*]/.test(s)) return s.split(/\/\//); /* split */
+const half = total / 2; // division
+const path = url.replace(/^\/+/, "") // leading slashes
+`,
+			want: `const isUrl = (u) => /https?:\/\//.test(u);
+if (/[/*]/.test(s)) return s.split(/\/\//);
+const half = total / 2;
+const path = url.replace(/^\/+/, "")
+`,
+		},
+		{
+			name: "Python file",
+			path: "tool.py",
+			content: `#!/usr/bin/env python3
+# License header
+
+def main():
+    """Docstring # kept"""
+    print("# not a comment")  # comment
+`,
+			want: `#!/usr/bin/env python3
+
+def main():
+    """Docstring # kept"""
+    print("# not a comment")
+`,
+		},
+		{
+			name: "Shell file",
+			path: "build.sh",
+			content: `#!/bin/sh
+# build the thing
+echo "$#" 'a # b' c#d # comment
+`,
+			want: `#!/bin/sh
+echo "$#" 'a # b' c#d
+`,
+		},
+		{
+			name: "YAML file",
+			path: "config.yaml",
+			content: `# settings
+name: demo # inline
+color: "#fff"
+
+
+tags: [a, b]
+`,
+			want: `name: demo
+color: "#fff"
+
+tags: [a, b]
+`,
+		},
+		{
+			name:    "Unsupported file",
+			path:    "README.md",
+			content: "# Title\n\n\n\nText\n",
+			want:    "# Title\n\n\n\nText\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := New(&Config{Minify: true}, nil)
+			f := &File{Path: tt.path, Content: tt.content}
+
+			assert.NoError(t, p.minify(f))
+			assert.Equal(t, tt.want, f.Content)
+			if tt.want != tt.content {
+				assert.Equal(t, EstimateTokens(tt.content), f.originalTokens)
+				assert.Equal(t, EstimateTokens(tt.content)-EstimateTokens(tt.want), f.savedTokens)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package processor
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"path"
+	"strings"
+)
+
+const (
+	ModeFull    = "full"
+	ModeOutline = "outline"
+)
+
+var modes = []string{ModeFull, ModeOutline}
+
+// outline is the pipeline transform that reduces Go files to their package
+// clause, imports, constants, type declarations, function signatures and doc
+// comments. Files matching a Full pattern, and files that fail to parse, are
+// left untouched.
+func (p *Processor) outline(f *File) error {
+	if !strings.HasSuffix(f.Path, ".go") || matchesAny(f.Path, p.config.Full) {
+		return nil
+	}
+
+	content, ok := outlineGo(f.Content)
+	if ok {
+		f.Content = content
+	}
+	return nil
+}
+
+func outlineGo(src string) (string, bool) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return "", false
+	}
+
+	// Kept comments must fall within one of these ranges
+	type span struct{ start, end token.Pos }
+	var spans []span
+	if file.Doc != nil {
+		spans = append(spans, span{file.Doc.Pos(), file.Doc.End()})
+	}
+
+	var decls []ast.Decl
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			d.Body = nil
+			decls = append(decls, d)
+			spans = append(spans, span{d.Pos(), d.End()})
+		case *ast.GenDecl:
+			if d.Tok == token.VAR {
+				continue
+			}
+			decls = append(decls, d)
+			spans = append(spans, span{d.Pos(), d.End()})
+		}
+		// Doc comments start before the declaration itself
+		if doc := docOf(decl); doc != nil {
+			spans = append(spans, span{doc.Pos(), doc.End()})
+		}
+	}
+	file.Decls = decls
+
+	var comments []*ast.CommentGroup
+	for _, cg := range file.Comments {
+		for _, s := range spans {
+			if cg.Pos() >= s.start && cg.End() <= s.end {
+				comments = append(comments, cg)
+				break
+			}
+		}
+	}
+	file.Comments = comments
+
+	var buf bytes.Buffer
+	cfg := printer.Config{Mode: printer.UseSpaces | printer.TabIndent, Tabwidth: 8}
+	if err := cfg.Fprint(&buf, fset, file); err != nil {
+		return "", false
+	}
+	return buf.String(), true
+}
+
+func docOf(decl ast.Decl) *ast.CommentGroup {
+	switch d := decl.(type) {
+	case *ast.FuncDecl:
+		return d.Doc
+	case *ast.GenDecl:
+		if d.Tok == token.VAR {
+			return nil
+		}
+		return d.Doc
+	}
+	return nil
+}
+
+// matchesAny reports whether the path, or its base name, matches any of the
+// glob patterns.
+func matchesAny(filePath string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, filePath); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, path.Base(filePath)); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,117 @@
+package processor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const outlineSource = `// Copyright 2024 Someone
+// License header.
+
+// Package demo does things.
+package demo
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Answer is the answer.
+const Answer = 42
+
+var cache = map[string]int{}
+
+// Greeter greets.
+type Greeter struct {
+	// Name of the greeter.
+	Name string // trailing
+}
+
+// Greet returns a greeting.
+func (g Greeter) Greet(to string) string {
+	// build it
+	return fmt.Sprintf("%s greets %s", g.Name, strings.ToUpper(to))
+}
+
+func helper() {
+	// inner comment
+	fmt.Println("x")
+}
+`
+
+const outlineWant = `// Package demo does things.
+package demo
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Answer is the answer.
+const Answer = 42
+
+// Greeter greets.
+type Greeter struct {
+	// Name of the greeter.
+	Name string // trailing
+}
+
+// Greet returns a greeting.
+func (g Greeter) Greet(to string) string
+
+func helper()
+`
+
+func TestOutline(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		content string
+		full    []string
+		want    string
+	}{
+		{
+			name:    "Go file",
+			path:    "demo/demo.go",
+			content: outlineSource,
+			want:    outlineWant,
+		},
+		{
+			name:    "Go file matching a full pattern",
+			path:    "demo/demo.go",
+			content: outlineSource,
+			full:    []string{"demo.go"},
+			want:    outlineSource,
+		},
+		{
+			name:    "Go file matching a full path pattern",
+			path:    "demo/demo.go",
+			content: outlineSource,
+			full:    []string{"demo/*"},
+			want:    outlineSource,
+		},
+		{
+			name:    "Invalid Go file",
+			path:    "broken.go",
+			content: "package broken\n\nfunc {",
+			want:    "package broken\n\nfunc {",
+		},
+		{
+			name:    "Non Go file",
+			path:    "README.md",
+			content: "# Demo",
+			want:    "# Demo",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := New(&Config{Mode: ModeOutline, Full: tt.full}, nil)
+			f := &File{Path: tt.path, Content: tt.content}
+
+			assert.NoError(t, p.outline(f))
+			assert.Equal(t, tt.want, f.Content)
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package processor
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/iamhectorsosa/octomap/pkg/compress"
+)
+
+const (
+	OnConflictOverwrite = "overwrite"
+	OnConflictSuffix    = "suffix"
+	OnConflictFail      = "fail"
+	OnConflictAppend    = "append"
+
+	errOutputExists    = "output file already exists, received %q\n"
+	errCreateOutputDir = "unable to create output directory: %q\n%v\n"
+)
+
+var conflictPolicies = []string{OnConflictOverwrite, OnConflictSuffix, OnConflictFail, OnConflictAppend}
+
+// Placeholders of output templates. date and time depend on the wall clock.
+var (
+	outputPlaceholders = []string{"owner", "repo", "ref", "commit", "date", "time", "ext"}
+	clockPlaceholders  = []string{"date", "time"}
+	placeholderPattern = regexp.MustCompile(`\{([^{}]*)\}`)
+)
+
+// isOutputFile reports whether an output names a file or a template rather
+// than a directory.
+func isOutputFile(output string) bool {
+	return filepath.Ext(output) != "" || strings.ContainsAny(output, "{}")
+}
+
+// reportExt returns the extension of the report, without the dot.
+func (p *Processor) reportExt() string {
+	switch {
+	case p.config.Format == FormatSQLite:
+		return "db"
+	case p.config.TreeOnly && p.config.TreeFormat == TreeFormatASCII:
+		return "txt"
+	}
+	return "json"
+}
+
+// reportFile resolves the path the report is saved to from the output
+// template or directory, creating its directory when asked. With the fail
+// policy an existing report is reported before any work is done, conflicts
+// are otherwise resolved when the report is published.
+func (p *Processor) reportFile(ext string, now time.Time) (string, error) {
+	filePath := filepath.Join(p.config.Output, p.baseName()+"."+ext)
+	if p.config.OutputTemplate != "" {
+		filePath = p.expandOutput(p.config.OutputTemplate, ext, now)
+	}
+	if suffix := compress.Ext(p.config.Compress); !strings.HasSuffix(filePath, suffix) {
+		filePath += suffix
+	}
+
+	dir := filepath.Dir(filePath)
+	if p.config.MkDir {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return "", fmt.Errorf(errCreateOutputDir, dir, err)
+		}
+	} else if _, err := os.Stat(dir); err != nil {
+		return "", fmt.Errorf(errOutputDoesntExit, dir, err)
+	}
+
+	if p.config.OnConflict == OnConflictFail && exists(filePath) {
+		return "", fmt.Errorf(errOutputExists, filePath)
+	}
+	return filePath, nil
+}
+
+// expandOutput replaces the placeholders of an output template.
+func (p *Processor) expandOutput(template, ext string, now time.Time) string {
+	commit := p.commit[:min(commitLen, len(p.commit))]
+	if commit == "" {
+		commit = "unknown"
+	}
+	values := map[string]string{
+		"owner":  p.config.Owner,
+		"repo":   p.config.Repo,
+		"ref":    strings.ReplaceAll(p.config.Branch, "/", "-"),
+		"commit": commit,
+		"date":   now.Format("2006-01-02"),
+		"time":   now.Format("150405"),
+		"ext":    ext,
+	}
+	return placeholderPattern.ReplaceAllStringFunc(template, func(match string) string {
+		return values[match[1:len(match)-1]]
+	})
+}
+
+// publish moves a complete temporary file to filePath and returns the path
+// it was published at. When overwriting it is renamed, replacing any existing
+// file. Otherwise it is hard linked, which never replaces a file, even one
+// created by another process since the name was chosen: fail returns an error
+// and suffix retries with the next numbered path such as report-1.json.
+func publish(tmpPath, filePath, policy string) (string, error) {
+	if policy != OnConflictSuffix && policy != OnConflictFail {
+		if err := os.Rename(tmpPath, filePath); err != nil {
+			return "", fmt.Errorf("unable to create file: %q\n %v", filePath, err)
+		}
+		return filePath, nil
+	}
+
+	base, ext := splitExt(filePath)
+	candidate := filePath
+	for n := 1; ; n++ {
+		err := os.Link(tmpPath, candidate)
+		if err == nil {
+			os.Remove(tmpPath)
+			return candidate, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return "", fmt.Errorf("unable to create file: %q\n %v", candidate, err)
+		}
+		if policy == OnConflictFail {
+			return "", fmt.Errorf(errOutputExists, candidate)
+		}
+		candidate = fmt.Sprintf("%s-%d%s", base, n, ext)
+	}
+}
+
+// sidecarPath returns the path of a file written next to the report, such
+// as report.manifest.json for report.json.
+func sidecarPath(reportPath, kind string) string {
+	base, _ := splitExt(reportPath)
+	return base + "." + kind + ".json"
+}
+
+// splitExt splits a path into its base and extension, keeping a compression
+// extension with the one before it, as in .json.gz.
+func splitExt(filePath string) (base, ext string) {
+	ext = filepath.Ext(filePath)
+	base = strings.TrimSuffix(filePath, ext)
+	if compress.HasExt(ext) {
+		inner := filepath.Ext(base)
+		base, ext = strings.TrimSuffix(base, inner), inner+ext
+	}
+	return base, ext
+}
+
+func exists(filePath string) bool {
+	_, err := os.Lstat(filePath)
+	return err == nil
+}
+
+// writeAtomic has write create the file at a temporary path in the same
+// directory and publishes it at filePath with the conflict policy once
+// complete, so readers never see a partial file. It returns the path written.
+func writeAtomic(filePath, policy string, write func(tmpPath string) error) (string, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), "."+filepath.Base(filePath)+".*.tmp")
+	if err != nil {
+		return "", fmt.Errorf("unable to create file: %q\n %v", filePath, err)
+	}
+	tmpPath := tmp.Name()
+	tmp.Close()
+
+	if err := write(tmpPath); err != nil {
+		os.Remove(tmpPath)
+		return "", err
+	}
+	if err := os.Chmod(tmpPath, 0644); err != nil {
+		os.Remove(tmpPath)
+		return "", fmt.Errorf("unable to create file: %q\n %v", filePath, err)
+	}
+	published, err := publish(tmpPath, filePath, policy)
+	if err != nil {
+		os.Remove(tmpPath)
+		return "", err
+	}
+	return published, nil
+}
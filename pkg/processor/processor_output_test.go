@@ -0,0 +1,152 @@
+package processor
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/iamhectorsosa/octomap/pkg/compress"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandOutput(t *testing.T) {
+	p := New(&Config{Owner: "user", Repo: "repo", Branch: "feature/x"}, nil)
+	now := time.Date(2024, 3, 9, 14, 5, 6, 0, time.UTC)
+
+	assert.Equal(t, "user/repo@feature-x-2024-03-09.json", p.expandOutput("{owner}/{repo}@{ref}-{date}.{ext}", "json", now))
+	assert.Equal(t, "repo-unknown-140506.db", p.expandOutput("{repo}-{commit}-{time}.{ext}", "db", now))
+
+	p.commit = testCommit
+	assert.Equal(t, "repo-0123456789ab.json", p.expandOutput("{repo}-{commit}.json", "txt", now))
+}
+
+func TestPublish(t *testing.T) {
+	tmpDir := t.TempDir()
+	filePath := filepath.Join(tmpDir, "report.json")
+	newTmp := func(content string) string {
+		tmp := filepath.Join(tmpDir, ".report.json.tmp")
+		require.NoError(t, os.WriteFile(tmp, []byte(content), 0644))
+		return tmp
+	}
+	read := func(name string) string {
+		content, err := os.ReadFile(name)
+		require.NoError(t, err)
+		return string(content)
+	}
+
+	got, err := publish(newTmp("first"), filePath, OnConflictFail)
+	require.NoError(t, err)
+	assert.Equal(t, filePath, got)
+	assert.Equal(t, "first", read(filePath))
+
+	// The existing file is kept, even though no check ran beforehand
+	tmp := newTmp("second")
+	_, err = publish(tmp, filePath, OnConflictFail)
+	assert.EqualError(t, err, fmt.Sprintf(errOutputExists, filePath))
+	assert.Equal(t, "first", read(filePath))
+
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "report-1.json"), nil, 0644))
+	got, err = publish(tmp, filePath, OnConflictSuffix)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(tmpDir, "report-2.json"), got)
+	assert.Equal(t, "second", read(got))
+	assert.NoFileExists(t, tmp)
+
+	got, err = publish(newTmp("third"), filePath, OnConflictOverwrite)
+	require.NoError(t, err)
+	assert.Equal(t, filePath, got)
+	assert.Equal(t, "third", read(filePath))
+}
+
+func TestProcessOutputTemplate(t *testing.T) {
+	archive := newTarGz(t, map[string]string{
+		"repo-main/main.go": "package main\n",
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(archive)
+	}))
+	defer server.Close()
+
+	tmpDir := t.TempDir()
+	newProcessor := func(onConflict string, mkdir bool) *Processor {
+		return New(&Config{
+			Owner:          "user",
+			Repo:           "repo",
+			Branch:         "main",
+			Url:            server.URL,
+			Dir:            "repo-main",
+			OutputTemplate: filepath.Join(tmpDir, "{owner}", "{repo}@{ref}.{ext}"),
+			OnConflict:     onConflict,
+			MkDir:          mkdir,
+		}, nil)
+	}
+
+	_, err := newProcessor(OnConflictOverwrite, false).Process()
+	assert.Error(t, err)
+
+	p := newProcessor(OnConflictOverwrite, true)
+	_, err = p.Process()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(tmpDir, "user", "repo@main.json"), p.ReportPath())
+	assert.FileExists(t, filepath.Join(tmpDir, "user", "repo@main.manifest.json"))
+
+	p = newProcessor(OnConflictSuffix, true)
+	_, err = p.Process()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(tmpDir, "user", "repo@main-1.json"), p.ReportPath())
+	assert.FileExists(t, filepath.Join(tmpDir, "user", "repo@main-1.manifest.json"))
+
+	_, err = newProcessor(OnConflictFail, true).Process()
+	assert.Error(t, err)
+
+	// Files are renamed into place, leaving no temporary files behind
+	entries, err := os.ReadDir(filepath.Join(tmpDir, "user"))
+	require.NoError(t, err)
+	assert.Len(t, entries, 4)
+}
+
+func TestProcessCompressed(t *testing.T) {
+	archive := newTarGz(t, map[string]string{
+		"repo-main/main.go": "package main\n",
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(archive)
+	}))
+	defer server.Close()
+
+	tmpDir := t.TempDir()
+	config := &Config{
+		Repo:           "repo",
+		Url:            server.URL,
+		Dir:            "repo-main",
+		OutputTemplate: filepath.Join(tmpDir, "{repo}.json"),
+		OnConflict:     OnConflictSuffix,
+		Compress:       compress.Gzip,
+	}
+	p := New(config, nil)
+	_, err := p.Process()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(tmpDir, "repo.json.gz"), p.ReportPath())
+	assert.FileExists(t, filepath.Join(tmpDir, "repo.manifest.json"))
+
+	f, err := os.Open(p.ReportPath())
+	require.NoError(t, err)
+	defer f.Close()
+	r, err := compress.NewReader(f)
+	require.NoError(t, err)
+	var data RepositoryData
+	require.NoError(t, json.NewDecoder(r).Decode(&data))
+	assert.Equal(t, RepositoryData{"main.go": "package main\n"}, data)
+
+	p = New(config, nil)
+	_, err = p.Process()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(tmpDir, "repo-1.json.gz"), p.ReportPath())
+	assert.FileExists(t, filepath.Join(tmpDir, "repo-1.manifest.json"))
+}
@@ -0,0 +1,169 @@
+package processor
+
+import (
+	"sync"
+
+	"github.com/iamhectorsosa/octomap/pkg/archive"
+)
+
+// File is a mapped file flowing through the post-processing pipeline. Files
+// marked Drop are left out of the repository data.
+type File struct {
+	Path       string
+	Content    string
+	Language   string
+	Redactions []Redaction
+	Size       int64
+	Drop       bool
+
+	// Token estimates recorded by minify, before minifying and saved by it
+	originalTokens int
+	savedTokens    int
+
+	// LFS pointer whose object is resolved once the archive is read
+	pointer *archive.LFSPointer
+}
+
+// transform is a per-file step applied concurrently by the pipeline workers.
+type transform func(f *File) error
+
+type job struct {
+	index int
+	file  *File
+}
+
+type result struct {
+	err   error
+	file  *File
+	index int
+}
+
+// pipeline applies transforms on a bounded pool of workers and hands results
+// back to the sink in submission order, regardless of which worker finishes
+// first.
+type pipeline struct {
+	transforms []transform
+	jobs       chan job
+	results    chan result
+	inflight   chan struct{}
+	done       chan struct{}
+	doneOnce   sync.Once
+	wg         sync.WaitGroup
+	next       int
+}
+
+func newPipeline(workers int, transforms []transform) *pipeline {
+	if workers < 1 {
+		workers = 1
+	}
+
+	pl := &pipeline{
+		transforms: transforms,
+		jobs:       make(chan job, workers),
+		results:    make(chan result, workers),
+		inflight:   make(chan struct{}, 2*workers),
+		done:       make(chan struct{}),
+	}
+
+	pl.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go pl.work()
+	}
+	go func() {
+		pl.wg.Wait()
+		close(pl.results)
+	}()
+
+	return pl
+}
+
+func (pl *pipeline) work() {
+	defer pl.wg.Done()
+	for {
+		var j job
+		select {
+		case next, ok := <-pl.jobs:
+			if !ok {
+				return
+			}
+			j = next
+		case <-pl.done:
+			return
+		}
+
+		var err error
+		for _, t := range pl.transforms {
+			if err = t(j.file); err != nil {
+				break
+			}
+		}
+		select {
+		case pl.results <- result{index: j.index, file: j.file, err: err}:
+		case <-pl.done:
+			return
+		}
+	}
+}
+
+// submit queues a file for processing, blocking while too many files are in
+// flight. It returns false once the pipeline has been aborted.
+func (pl *pipeline) submit(f *File) bool {
+	select {
+	case pl.inflight <- struct{}{}:
+	case <-pl.done:
+		return false
+	}
+
+	select {
+	case pl.jobs <- job{index: pl.next, file: f}:
+		pl.next++
+		return true
+	case <-pl.done:
+		return false
+	}
+}
+
+// close signals that no more files will be submitted.
+func (pl *pipeline) close() {
+	close(pl.jobs)
+}
+
+// abort stops the workers and unblocks any pending submit.
+func (pl *pipeline) abort() {
+	pl.doneOnce.Do(func() { close(pl.done) })
+}
+
+// drain passes each processed file to sink in submission order. It stops
+// handing out files after the first error but keeps draining until the
+// workers have exited.
+func (pl *pipeline) drain(sink func(f *File) error) error {
+	var firstErr error
+	pending := make(map[int]result)
+	next := 0
+
+	for r := range pl.results {
+		pending[r.index] = r
+		for {
+			r, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			next++
+			<-pl.inflight
+
+			if firstErr != nil {
+				continue
+			}
+			if r.err == nil {
+				r.err = sink(r.file)
+			}
+			if r.err != nil {
+				firstErr = r.err
+				pl.abort()
+			}
+		}
+	}
+
+	return firstErr
+}
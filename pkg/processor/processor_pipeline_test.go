@@ -0,0 +1,99 @@
+package processor
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPipelineOrdering(t *testing.T) {
+	upper := func(f *File) error {
+		time.Sleep(time.Duration(rand.Intn(500)) * time.Microsecond)
+		f.Content = strings.ToUpper(f.Content)
+		return nil
+	}
+
+	for _, workers := range []int{0, 1, 4, 16} {
+		t.Run(fmt.Sprintf("%d workers", workers), func(t *testing.T) {
+			pl := newPipeline(workers, []transform{upper})
+
+			var got []string
+			sinkErr := make(chan error, 1)
+			go func() {
+				sinkErr <- pl.drain(func(f *File) error {
+					got = append(got, f.Path+"="+f.Content)
+					return nil
+				})
+			}()
+
+			var want []string
+			for i := 0; i < 200; i++ {
+				path := fmt.Sprintf("file%03d.go", i)
+				require.True(t, pl.submit(&File{Path: path, Content: "content"}))
+				want = append(want, path+"=CONTENT")
+			}
+			pl.close()
+
+			require.NoError(t, <-sinkErr)
+			assert.Equal(t, want, got)
+		})
+	}
+}
+
+func TestPipelineTransformError(t *testing.T) {
+	errBroken := errors.New("broken file")
+	failing := func(f *File) error {
+		if f.Path == "file010.go" {
+			return errBroken
+		}
+		return nil
+	}
+
+	pl := newPipeline(4, []transform{failing})
+
+	var got []string
+	sinkErr := make(chan error, 1)
+	go func() {
+		sinkErr <- pl.drain(func(f *File) error {
+			got = append(got, f.Path)
+			return nil
+		})
+	}()
+
+	for i := 0; i < 200; i++ {
+		if !pl.submit(&File{Path: fmt.Sprintf("file%03d.go", i)}) {
+			break
+		}
+	}
+	pl.close()
+
+	assert.ErrorIs(t, <-sinkErr, errBroken)
+	assert.Len(t, got, 10)
+}
+
+func TestPipelineSinkError(t *testing.T) {
+	errSink := errors.New("sink failed")
+	pl := newPipeline(4, nil)
+
+	sinkErr := make(chan error, 1)
+	go func() {
+		sinkErr <- pl.drain(func(f *File) error {
+			return errSink
+		})
+	}()
+
+	for i := 0; i < 200; i++ {
+		if !pl.submit(&File{Path: fmt.Sprintf("file%03d.go", i)}) {
+			break
+		}
+	}
+	pl.close()
+
+	assert.ErrorIs(t, <-sinkErr, errSink)
+}
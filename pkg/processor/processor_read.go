@@ -4,18 +4,38 @@ import (
 	"fmt"
 	"io"
 	"strings"
-	"time"
 
 	"github.com/iamhectorsosa/octomap/pkg/archive"
 )
 
-func (p *Processor) read(reader io.Reader, stagger time.Duration) error {
+func (p *Processor) read(reader io.Reader) error {
 	tarReader, err := archive.NewTarGzReader(reader)
 	if err != nil {
 		return err
 	}
 	defer tarReader.Close()
 
+	pl := newPipeline(p.config.Jobs, p.transforms)
+	sinkErr := make(chan error, 1)
+	go func() {
+		sinkErr <- pl.drain(p.insert)
+	}()
+
+	readErr := p.readEntries(tarReader, pl.submit)
+	if readErr != nil {
+		pl.abort()
+	}
+	pl.close()
+
+	if err := <-sinkErr; err != nil {
+		return err
+	}
+	return readErr
+}
+
+// readEntries filters the archive entries, passing every file to be mapped
+// to emit until it returns false.
+func (p *Processor) readEntries(tarReader *archive.TarGzReader, emit func(f *File) bool) error {
 	for {
 		hdr, err := tarReader.ReadNext()
 		if err == io.EOF {
@@ -25,6 +45,14 @@ func (p *Processor) read(reader io.Reader, stagger time.Duration) error {
 			return err
 		}
 
+		if hdr.Commit != "" {
+			p.commit = hdr.Commit
+		}
+		if p.config.Gitignore && !p.ignoreSettled && pastGitignore(repoPath(hdr.Name)) {
+			if !p.settleGitignore(emit) {
+				return nil
+			}
+		}
 		if hdr.IsDir {
 			p.dirCount++
 		}
@@ -32,60 +60,232 @@ func (p *Processor) read(reader io.Reader, stagger time.Duration) error {
 			p.fileCount++
 		}
 
-		if hdr.IsDir || !strings.HasPrefix(hdr.Name, p.config.Dir) {
+		if hdr.IsDir {
 			continue
 		}
 
-		relativePath := strings.TrimPrefix(hdr.Name, p.config.Dir+"/")
-
-		shouldProcess := len(p.config.Include) == 0
+		// .gitmodules declares the submodules and .gitignore the ignored
+		// files even when they are not mapped
+		var cached *string
+		if hdr.IsFile && p.config.Submodules != "" && p.config.Submodules != SubmodulesSkip && repoPath(hdr.Name) == ".gitmodules" {
+			content, err := tarReader.ReadContent()
+			if err != nil {
+				return err
+			}
+			p.gitmodules, cached = content, &content
+		}
+		if hdr.IsFile && p.config.Gitignore && !p.ignoreSettled && repoPath(hdr.Name) == gitignoreFile {
+			content, err := tarReader.ReadContent()
+			if err != nil {
+				return err
+			}
+			p.ignoreRules, cached = parseGitignore(content), &content
+			if !p.settleGitignore(emit) {
+				return nil
+			}
+		}
 
-		for _, suffix := range p.config.Include {
-			if strings.HasSuffix(relativePath, suffix) {
-				shouldProcess = true
-				break
+		if !p.withinDirs(hdr.Name) {
+			if hdr.IsFile {
+				p.skip(repoPath(hdr.Name), SkipOutsideDir, "")
 			}
+			continue
 		}
 
-		if shouldProcess {
-			for _, suffix := range p.config.Exclude {
-				if strings.HasSuffix(relativePath, suffix) {
-					shouldProcess = false
-					break
-				}
+		relativePath := strings.TrimPrefix(hdr.Name, p.config.Dir+"/")
+
+		if p.ignoreSettled {
+			if rule, ok := p.gitignored(repoPath(hdr.Name)); ok {
+				p.skip(relativePath, SkipGitignored, rule)
+				continue
 			}
 		}
 
+		// Links are filtered through the files their target maps
+		if hdr.LinkType != "" {
+			p.readLink(hdr, relativePath)
+			continue
+		}
+
+		_, exclude, shouldProcess := p.matchRules(relativePath)
+		if exclude != "" {
+			p.skip(relativePath, SkipExcluded, exclude)
+			continue
+		}
 		if !shouldProcess {
+			p.skip(relativePath, SkipNotIncluded, "")
+			continue
+		}
+		if !p.selected(relativePath) {
+			p.skip(relativePath, SkipNotSelected, "")
+			continue
+		}
+		if !hdr.IsFile {
+			p.skip(relativePath, SkipSpecial, "")
+			continue
+		}
+		if p.config.MaxSize > 0 && hdr.Size > int64(p.config.MaxSize) {
+			p.skip(relativePath, SkipTooLarge, "")
 			continue
 		}
 
-		content, err := tarReader.ReadContent()
+		f := &File{Path: relativePath, Size: hdr.Size}
+		contentRead := false
+		readContent := func() (string, error) {
+			contentRead = true
+			if cached != nil {
+				return *cached, nil
+			}
+			return tarReader.ReadContent()
+		}
+
+		shouldProcess, err = p.matchesLangs(f, readContent)
 		if err != nil {
 			return err
 		}
+		if !shouldProcess {
+			p.skip(relativePath, SkipLanguage, f.Language)
+			continue
+		}
 
-		pathParts := strings.Split(relativePath, "/")
-		current := p.data
-		for i, part := range pathParts {
-			if i == len(pathParts)-1 {
-				current[part] = content
-				p.dataFileCount++
-				p.update(fmt.Sprintf("mapped: %s", relativePath))
-				time.Sleep(stagger)
-				break
+		// Tree-only mapping reads contents only to filter them, and those
+		// of files small enough to be LFS pointers
+		filtered := contentRead || !p.config.TreeOnly || p.config.Contains != nil
+		if !contentRead && (filtered || hdr.Size <= archive.LFSPointerMaxSize) {
+			if f.Content, err = readContent(); err != nil {
+				return err
 			}
+		}
+		if pointer, ok := archive.ParseLFSPointer(f.Content); ok && !p.handleLFS(f, pointer) {
+			continue
+		}
+		// Resolved LFS objects are filtered once fetched
+		if f.pointer == nil && filtered && !p.filterContent(f) {
+			continue
+		}
+		if p.config.TreeOnly {
+			f.Content = ""
+		}
 
-			if _, exists := current[part]; !exists {
-				current[part] = make(map[string]interface{})
-			}
+		// Files read before .gitignore wait for it
+		if p.config.Gitignore && !p.ignoreSettled {
+			p.ignorePending = append(p.ignorePending, pendingFile{file: f, repoPath: repoPath(hdr.Name)})
+			continue
+		}
+		if !p.submit(f, emit) {
+			return nil
+		}
+	}
+
+	if p.config.Gitignore && !p.ignoreSettled && !p.settleGitignore(emit) {
+		return nil
+	}
+	p.resolveLFS(emit)
+	return nil
+}
+
+// filterContent applies the binary and content filters to a file, reporting
+// whether it is still mapped.
+func (p *Processor) filterContent(f *File) bool {
+	if isBinary(f.Content) {
+		p.skip(f.Path, SkipBinary, "")
+		return false
+	}
+	if p.config.Contains != nil && !p.config.Contains.MatchString(f.Content) {
+		p.skip(f.Path, SkipNoMatch, p.config.Contains.String())
+		return false
+	}
+	return true
+}
+
+// matchRules returns the include and exclude rules deciding whether a file
+// is mapped. include is empty when no include rule matched and exclude is
+// empty when no exclude rule dropped the file.
+func (p *Processor) matchRules(relativePath string) (include, exclude string, ok bool) {
+	ok = len(p.config.Include) == 0
+
+	for _, suffix := range p.config.Include {
+		if strings.HasSuffix(relativePath, suffix) {
+			include = suffix
+			ok = true
+			break
+		}
+	}
+
+	if !ok {
+		return include, exclude, ok
+	}
 
-			var ok bool
-			current, ok = current[part].(map[string]interface{})
-			if !ok {
-				return fmt.Errorf("unexpected structure found on: %s", hdr.Name)
+	for _, suffix := range p.config.Exclude {
+		if strings.HasSuffix(relativePath, suffix) {
+			exclude = suffix
+			ok = false
+			break
+		}
+	}
+
+	return include, exclude, ok
+}
+
+// selected reports whether a file is part of the explicit path selection,
+// which includes every file when no paths are given.
+func (p *Processor) selected(relativePath string) bool {
+	return p.paths == nil || p.paths[relativePath]
+}
+
+// withinDirs reports whether an archive entry falls under the target
+// directories, matching whole path segments only.
+func (p *Processor) withinDirs(name string) bool {
+	if len(p.config.Dirs) == 0 {
+		return strings.HasPrefix(name, p.config.Dir+"/")
+	}
+	for _, dir := range p.config.Dirs {
+		if strings.HasPrefix(name, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// insert places a processed file into the nested repository data.
+func (p *Processor) insert(f *File) error {
+	for _, r := range f.Redactions {
+		p.redactions = append(p.redactions, r)
+		p.update(fmt.Sprintf("redacted: %s:%d (%s)", r.Path, r.Line, r.Rule))
+	}
+	if f.Drop {
+		p.dropped = append(p.dropped, Skip{Path: f.Path, Reason: SkipRedacted})
+		p.update(fmt.Sprintf("dropped: %s", f.Path))
+		return nil
+	}
+
+	p.originalTokens += f.originalTokens
+	p.savedTokens += f.savedTokens
+	p.count(f)
+	p.record(f)
+
+	pathParts := strings.Split(f.Path, "/")
+	current := p.data
+	for i, part := range pathParts {
+		if i == len(pathParts)-1 {
+			if p.config.TreeOnly {
+				current[part] = f.Size
+			} else {
+				current[part] = f.Content
 			}
+			p.dataFileCount++
+			p.update(fmt.Sprintf("mapped: %s", f.Path))
+			break
+		}
+
+		if _, exists := current[part]; !exists {
+			current[part] = make(map[string]interface{})
+		}
 
+		var ok bool
+		current, ok = current[part].(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("unexpected structure found on: %s", f.Path)
 		}
 	}
 	return nil
@@ -0,0 +1,156 @@
+package processor
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	RedactNone     = "none"
+	RedactMask     = "mask"
+	RedactDropFile = "drop-file"
+	RedactFail     = "fail"
+
+	errSecretDetected = "secret detected: %s:%d (%s)"
+)
+
+var redactModes = []string{RedactNone, RedactMask, RedactDropFile, RedactFail}
+
+var builtinRedactRules = []RedactRule{
+	{
+		Name:    "aws-access-key-id",
+		Pattern: regexp.MustCompile(`\b(?:AKIA|ASIA|ABIA|ACCA)[0-9A-Z]{16}\b`),
+	},
+	{
+		Name:    "aws-secret-access-key",
+		Pattern: regexp.MustCompile(`(?i)aws_?secret_?access_?key["']?\s*[:=]\s*["']?([A-Za-z0-9/+=]{40})\b`),
+	},
+	{
+		Name:    "gcp-api-key",
+		Pattern: regexp.MustCompile(`\bAIza[0-9A-Za-z_\-]{35}`),
+	},
+	{
+		Name:    "github-token",
+		Pattern: regexp.MustCompile(`\b(?:(?:ghp|gho|ghu|ghs|ghr)_[A-Za-z0-9]{36}|github_pat_[A-Za-z0-9_]{82})\b`),
+	},
+	{
+		Name:    "private-key",
+		Pattern: regexp.MustCompile(`-----BEGIN[ A-Z0-9]*PRIVATE KEY( BLOCK)?-----[\s\S]*?-----END[ A-Z0-9]*PRIVATE KEY( BLOCK)?-----`),
+	},
+	{
+		Name:    "jwt",
+		Pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_\-]{10,}\.eyJ[A-Za-z0-9_\-]{10,}\.[A-Za-z0-9_\-]{10,}`),
+	},
+	{
+		// Quoted strings and assigned values that look like random tokens
+		Name:       "high-entropy",
+		Pattern:    regexp.MustCompile("(?:[\"'`]|=[ \t]*|:[ \t]+)([A-Za-z0-9+/=_\\-]{24,})"),
+		MinEntropy: 4.2,
+	},
+}
+
+type match struct {
+	rule       string
+	start, end int
+}
+
+// redact is the pipeline transform that scans a file for secrets and applies
+// the configured redaction mode.
+func (p *Processor) redact(f *File) error {
+	matches := findSecrets(f.Content, p.config.RedactRules)
+	if len(matches) == 0 {
+		return nil
+	}
+
+	for _, m := range matches {
+		f.Redactions = append(f.Redactions, Redaction{
+			Path: f.Path,
+			Line: strings.Count(f.Content[:m.start], "\n") + 1,
+			Rule: m.rule,
+		})
+	}
+
+	switch p.config.Redact {
+	case RedactFail:
+		r := f.Redactions[0]
+		return fmt.Errorf(errSecretDetected, r.Path, r.Line, r.Rule)
+	case RedactDropFile:
+		f.Drop = true
+	case RedactMask:
+		f.Content = maskSecrets(f.Content, matches)
+	}
+	return nil
+}
+
+// findSecrets returns non-overlapping matches ordered by position. When
+// matches overlap, the earliest wins, then the rule listed first.
+func findSecrets(content string, rules []RedactRule) []match {
+	var matches []match
+	for _, rule := range rules {
+		for _, loc := range rule.Pattern.FindAllStringSubmatchIndex(content, -1) {
+			start, end := loc[0], loc[1]
+			if len(loc) >= 4 && loc[2] >= 0 {
+				start, end = loc[2], loc[3]
+			}
+			if rule.MinEntropy > 0 && !looksRandom(content[start:end], rule.MinEntropy) {
+				continue
+			}
+			matches = append(matches, match{rule: rule.Name, start: start, end: end})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].start < matches[j].start
+	})
+
+	var kept []match
+	for _, m := range matches {
+		if len(kept) > 0 && m.start < kept[len(kept)-1].end {
+			continue
+		}
+		kept = append(kept, m)
+	}
+	return kept
+}
+
+func maskSecrets(content string, matches []match) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		b.WriteString(content[last:m.start])
+		b.WriteString(fmt.Sprintf("[REDACTED:%s]", m.rule))
+		last = m.end
+	}
+	b.WriteString(content[last:])
+	return b.String()
+}
+
+// looksRandom reports whether s has at least the given Shannon entropy per
+// character and mixes letters with digits, which rules out most identifiers.
+func looksRandom(s string, minEntropy float64) bool {
+	var hasLetter, hasDigit bool
+	counts := make(map[rune]int)
+	for _, r := range s {
+		counts[r]++
+		switch {
+		case r >= '0' && r <= '9':
+			hasDigit = true
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+			hasLetter = true
+		}
+	}
+	if !hasLetter || !hasDigit {
+		return false
+	}
+
+	var entropy float64
+	n := float64(len(s))
+	for _, c := range counts {
+		p := float64(c) / n
+		entropy -= p * math.Log2(p)
+	}
+	return entropy >= minEntropy
+}
@@ -0,0 +1,157 @@
+package processor
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Secrets are assembled at runtime so the test file itself does not trip
+// secret scanners.
+var (
+	awsKeyID    = "AKIA" + "IOSFODNN7EXAMPLE"
+	awsSecret   = "wJalrXUtnFEMI/K7MDENG/" + "bPxRfiCYEXAMPLEKEY"
+	gcpKey      = "AIza" + "SyA1b2C3d4E5f6G7h8I9j0KlMnOpQrStUvW"
+	githubToken = "ghp_" + "aB3dE5gH7jK9mN1pQ3sT5vW7yZ9bC1dF3hJ5"
+	jwtToken    = "eyJhbGciOiJIUzI1NiJ9." + "eyJzdWIiOiIxMjM0NTY3ODkwIn0." + "dozjgNryP4J3jVmNHl0w5N_XgL0n3I9PlFUP0THsR8U"
+	privateKey  = "-----BEGIN RSA " + "PRIVATE KEY-----\nMIIEowIBAAKCAQEA\n-----END RSA PRIVATE KEY-----"
+	randomToken = "Zq8X2vR7kLp3Nw9Ty4Hb6Mc1Jd5Fs0Ga"
+)
+
+func TestFindSecrets(t *testing.T) {
+	rules, err := createRedactRules([]string{`internal_[a-z]{8}`})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{
+			name:    "AWS access key ID",
+			content: fmt.Sprintf("key = %s\n", awsKeyID),
+			want:    []string{"aws-access-key-id"},
+		},
+		{
+			name:    "AWS secret access key",
+			content: fmt.Sprintf("aws_secret_access_key = %s\n", awsSecret),
+			want:    []string{"aws-secret-access-key"},
+		},
+		{
+			name:    "GCP API key",
+			content: fmt.Sprintf("const key = %q", gcpKey),
+			want:    []string{"gcp-api-key"},
+		},
+		{
+			name:    "GitHub token",
+			content: fmt.Sprintf("GITHUB_TOKEN=%s", githubToken),
+			want:    []string{"github-token"},
+		},
+		{
+			name:    "Private key block",
+			content: privateKey,
+			want:    []string{"private-key"},
+		},
+		{
+			name:    "JWT",
+			content: "Authorization: Bearer " + jwtToken,
+			want:    []string{"jwt"},
+		},
+		{
+			name:    "High entropy string",
+			content: fmt.Sprintf("token: %q", randomToken),
+			want:    []string{"high-entropy"},
+		},
+		{
+			name:    "Custom pattern",
+			content: "secret internal_abcdefgh",
+			want:    []string{"custom-1"},
+		},
+		{
+			name:    "Plain code",
+			content: "package main\n\nfunc main() {\n\tfmt.Println(\"TestFindSecretsWithLongIdentifier\")\n}\n",
+		},
+		{
+			name:    "Go checksum",
+			content: "github.com/spf13/cobra v1.8.1 h1:e5/vxKd/rZsfSJMUX1agtjeTDf+qv1/JdBF8gg5k9ZM=\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, m := range findSecrets(tt.content, rules) {
+				got = append(got, m.rule)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRedact(t *testing.T) {
+	rules, err := createRedactRules(nil)
+	require.NoError(t, err)
+
+	content := fmt.Sprintf("package config\n\nconst key = %q\n", awsKeyID)
+
+	tests := []struct {
+		name     string
+		mode     string
+		wantErr  string
+		wantDrop bool
+		wantText string
+	}{
+		{
+			name:     "Mask",
+			mode:     RedactMask,
+			wantText: "package config\n\nconst key = \"[REDACTED:aws-access-key-id]\"\n",
+		},
+		{
+			name:     "Drop file",
+			mode:     RedactDropFile,
+			wantDrop: true,
+			wantText: content,
+		},
+		{
+			name:    "Fail",
+			mode:    RedactFail,
+			wantErr: fmt.Sprintf(errSecretDetected, "config.go", 3, "aws-access-key-id"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := New(&Config{Redact: tt.mode, RedactRules: rules}, nil)
+			f := &File{Path: "config.go", Content: content}
+
+			err := p.redact(f)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantDrop, f.Drop)
+			assert.Equal(t, tt.wantText, f.Content)
+			assert.Equal(t, []Redaction{{Path: "config.go", Line: 3, Rule: "aws-access-key-id"}}, f.Redactions)
+			assert.NotContains(t, fmt.Sprint(f.Redactions), awsKeyID)
+		})
+	}
+}
+
+func TestMaskSecretsOverlap(t *testing.T) {
+	rules := []RedactRule{
+		{Name: "long", Pattern: regexp.MustCompile(`abc123def`)},
+		{Name: "short", Pattern: regexp.MustCompile(`123`)},
+	}
+
+	content := "x abc123def y 123"
+	masked := maskSecrets(content, findSecrets(content, rules))
+
+	assert.Equal(t, "x [REDACTED:long] y [REDACTED:short]", masked)
+	assert.False(t, strings.Contains(masked, "abc123def"))
+}
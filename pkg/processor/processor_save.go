@@ -3,27 +3,94 @@ package processor
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
-	"path/filepath"
 	"time"
+
+	"github.com/iamhectorsosa/octomap/pkg/compress"
 )
 
+// save writes the report, then the redaction report, if any, and the
+// manifest next to it. The report is published first, as the conflict policy
+// decides its final name, which the other files are named after.
 func (p *Processor) save() error {
-	fileName := fmt.Sprintf("%s%s.json", p.config.Repo, time.Now().Format("20060102_150405"))
-	filePath := filepath.Join(p.config.Output, fileName)
+	if p.config.Deterministic {
+		p.sortOutput()
+	}
 
-	f, err := os.Create(filePath)
+	filePath, err := p.reportFile(p.reportExt(), time.Now())
 	if err != nil {
-		return fmt.Errorf("unable to create file: %q\n %v", filePath, err)
+		return err
 	}
-	defer f.Close()
 
-	encoder := json.NewEncoder(f)
-	encoder.SetIndent("", "  ")
-	if err := encoder.Encode(p.data); err != nil {
-		return fmt.Errorf("encoding file error: %v", err)
+	policy := p.config.OnConflict
+	switch {
+	case p.config.Format == FormatSQLite && policy == OnConflictAppend:
+		err = p.writeSQLite(filePath)
+	case p.config.Format == FormatSQLite:
+		filePath, err = writeAtomic(filePath, policy, p.writeSQLite)
+	case p.config.TreeOnly && p.config.TreeFormat == TreeFormatASCII:
+		filePath, err = writeFile(filePath, policy, p.config.Compress, func(w io.Writer) error {
+			_, err := io.WriteString(w, RenderTree(p.data))
+			return err
+		})
+	default:
+		filePath, err = writeJSON(filePath, policy, p.config.Compress, p.data)
+	}
+	if err != nil {
+		return err
 	}
 
+	if len(p.redactions) > 0 {
+		reportPath := sidecarPath(filePath, "redactions")
+		if _, err := writeJSON(reportPath, OnConflictOverwrite, compress.None, p.redactions); err != nil {
+			return err
+		}
+		p.update(fmt.Sprintf("generated redaction report: %s", reportPath))
+	}
+
+	manifestPath := sidecarPath(filePath, "manifest")
+	if _, err := writeJSON(manifestPath, OnConflictOverwrite, compress.None, p.Manifest()); err != nil {
+		return err
+	}
+	p.update(fmt.Sprintf("generated manifest: %s", manifestPath))
+
+	p.reportPath = filePath
 	p.update(fmt.Sprintf("generated report: %s", filePath))
 	return nil
 }
+
+// writeFile atomically writes a file through the given compression method
+// and returns the path written.
+func writeFile(filePath, policy, method string, write func(w io.Writer) error) (string, error) {
+	return writeAtomic(filePath, policy, func(tmpPath string) error {
+		f, err := os.Create(tmpPath)
+		if err != nil {
+			return fmt.Errorf("unable to create file: %q\n %v", filePath, err)
+		}
+		defer f.Close()
+
+		w, err := compress.NewWriter(f, method)
+		if err != nil {
+			return err
+		}
+		if err := write(w); err != nil {
+			return err
+		}
+		if err := w.Close(); err != nil {
+			return fmt.Errorf("unable to create file: %q\n %v", filePath, err)
+		}
+		return f.Close()
+	})
+}
+
+func writeJSON(filePath, policy, method string, v any) (string, error) {
+	return writeFile(filePath, policy, method, func(w io.Writer) error {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(v); err != nil {
+			return fmt.Errorf("encoding file error: %v", err)
+		}
+		return nil
+	})
+}
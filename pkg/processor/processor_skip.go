@@ -0,0 +1,74 @@
+package processor
+
+import (
+	"sort"
+	"strings"
+)
+
+// Reasons a file is left out of the report.
+const (
+	SkipOutsideDir  = "outside-dir"
+	SkipNotIncluded = "not-included"
+	SkipExcluded    = "excluded"
+	SkipLanguage    = "language"
+	SkipNotSelected = "not-selected"
+	SkipTooLarge    = "too-large"
+	SkipBinary      = "binary"
+	SkipNoMatch     = "no-match"
+	SkipRedacted    = "redacted"
+	SkipLinkEscape  = "symlink-escape"
+	SkipSpecial     = "special"
+	SkipGitignored  = "gitignored"
+
+	SkipLFS           = "lfs-pointer"
+	SkipLFSUnresolved = "lfs-unresolved"
+)
+
+// binarySniffLen is how much of a file is checked for NUL bytes, as git does.
+const binarySniffLen = 8000
+
+// skip records a file left out while reading the archive.
+func (p *Processor) skip(path, reason, rule string) {
+	p.skipped = append(p.skipped, Skip{Path: path, Reason: reason, Rule: rule})
+}
+
+// Skipped returns every file left out of the report, ordered by path.
+func (p *Processor) Skipped() []Skip {
+	skipped := make([]Skip, 0, len(p.skipped)+len(p.dropped))
+	skipped = append(skipped, p.skipped...)
+	skipped = append(skipped, p.dropped...)
+	sort.SliceStable(skipped, func(i, j int) bool {
+		return skipped[i].Path < skipped[j].Path
+	})
+	return skipped
+}
+
+// skipCounts returns the number of skipped files per reason, or nil when no
+// file was skipped.
+func (p *Processor) skipCounts() map[string]int {
+	if len(p.skipped)+len(p.dropped) == 0 {
+		return nil
+	}
+	counts := make(map[string]int)
+	for _, s := range p.skipped {
+		counts[s.Reason]++
+	}
+	for _, s := range p.dropped {
+		counts[s.Reason]++
+	}
+	return counts
+}
+
+// repoPath returns an archive entry name relative to the repository root.
+func repoPath(name string) string {
+	if _, path, ok := strings.Cut(name, "/"); ok {
+		return path
+	}
+	return name
+}
+
+// isBinary reports whether content looks binary, that is whether its start
+// contains a NUL byte.
+func isBinary(content string) bool {
+	return strings.IndexByte(content[:min(len(content), binarySniffLen)], 0) >= 0
+}
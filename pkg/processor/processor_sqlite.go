@@ -0,0 +1,198 @@
+package processor
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+const (
+	FormatJSON   = "json"
+	FormatSQLite = "sqlite"
+)
+
+var formats = []string{FormatJSON, FormatSQLite}
+
+// sqliteSchema creates a repos table holding one manifest per mapped
+// repository and a files table of their files, with an FTS5 index on
+// contents. A database can accumulate many repositories when reports are
+// appended to it. Tree-only reports leave content and sha256 NULL,
+// deterministic reports leave created_at NULL.
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS repos (
+	id INTEGER PRIMARY KEY,
+	repo TEXT NOT NULL,
+	url TEXT NOT NULL,
+	dir TEXT NOT NULL,
+	commit_sha TEXT,
+	files INTEGER NOT NULL,
+	bytes INTEGER NOT NULL,
+	tokens INTEGER NOT NULL,
+	manifest TEXT NOT NULL,
+	created_at TEXT
+);
+CREATE TABLE IF NOT EXISTS files (
+	repo_id INTEGER NOT NULL REFERENCES repos(id),
+	path TEXT NOT NULL,
+	dir TEXT NOT NULL,
+	name TEXT NOT NULL,
+	ext TEXT NOT NULL,
+	size INTEGER NOT NULL,
+	sha256 TEXT,
+	language TEXT NOT NULL,
+	tokens INTEGER NOT NULL,
+	content TEXT,
+	PRIMARY KEY (repo_id, path)
+);
+CREATE INDEX IF NOT EXISTS files_language ON files(language);
+CREATE VIRTUAL TABLE IF NOT EXISTS files_fts USING fts5(
+	path, content, content='files', content_rowid='rowid'
+);
+`
+
+// sqliteBusyTimeout is how long a write waits for another process appending
+// to the same database.
+const sqliteBusyTimeout = 30 * time.Second
+
+// record keeps the language of a mapped file for sinks that need more than
+// the nested report.
+func (p *Processor) record(f *File) {
+	if p.config.Format != FormatSQLite {
+		return
+	}
+	if p.fileLanguages == nil {
+		p.fileLanguages = make(map[string]string)
+	}
+	p.fileLanguages[f.Path] = f.Language
+}
+
+// writeSQLite adds the mapped files and the manifest to the SQLite database
+// at filePath, creating it if needed, in a single transaction.
+func (p *Processor) writeSQLite(filePath string) error {
+	dsn := fmt.Sprintf("file:%s?_pragma=busy_timeout(%d)", filePath, sqliteBusyTimeout.Milliseconds())
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return fmt.Errorf("unable to create file: %q\n %v", filePath, err)
+	}
+	defer db.Close()
+
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		return fmt.Errorf("unable to create database: %q\n %v", filePath, err)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("database error: %v", err)
+	}
+	defer tx.Rollback()
+
+	if err := p.insertSQLite(tx); err != nil {
+		return fmt.Errorf("database error: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("database error: %v", err)
+	}
+	return nil
+}
+
+func (p *Processor) insertSQLite(tx *sql.Tx) error {
+	manifest := p.Manifest()
+	manifestJSON, err := json.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+
+	var commit, createdAt any
+	if manifest.Commit != "" {
+		commit = manifest.Commit
+	}
+	if !p.config.Deterministic {
+		createdAt = time.Now().UTC().Format(time.RFC3339)
+	}
+
+	result, err := tx.Exec(
+		`INSERT INTO repos (repo, url, dir, commit_sha, files, bytes, tokens, manifest, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		manifest.Repo, manifest.Url, manifest.Dir, commit, manifest.Stats.Files, manifest.Stats.Bytes, manifest.Stats.Tokens,
+		string(manifestJSON), createdAt,
+	)
+	if err != nil {
+		return err
+	}
+	repoID, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	stmt, err := tx.Prepare(`INSERT INTO files (repo_id, path, dir, name, ext, size, sha256, language, tokens, content) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	ftsStmt, err := tx.Prepare(`INSERT INTO files_fts (rowid, path, content) VALUES (?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer ftsStmt.Close()
+
+	// Rows are built from the report itself, so contents are not kept twice
+	return walkData(p.data, "", func(filePath string, value interface{}) error {
+		dir := path.Dir(filePath)
+		if dir == "." {
+			dir = ""
+		}
+
+		var hash, content any
+		var size int64
+		var tokens int
+		switch value := value.(type) {
+		case string:
+			sum := sha256.Sum256([]byte(value))
+			hash, content = hex.EncodeToString(sum[:]), value
+			size, tokens = int64(len(value)), EstimateTokens(value)
+		case int64:
+			size, tokens = value, EstimateSizeTokens(value)
+		}
+
+		result, err := stmt.Exec(repoID, filePath, dir, path.Base(filePath), path.Ext(filePath), size, hash, p.fileLanguages[filePath], tokens, content)
+		if err != nil {
+			return err
+		}
+		rowID, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+		_, err = ftsStmt.Exec(rowID, filePath, content)
+		return err
+	})
+}
+
+// walkData calls fn with the path and value of every file of the nested
+// report below prefix, in path order.
+func walkData(data map[string]interface{}, prefix string, fn func(filePath string, value interface{}) error) error {
+	names := make([]string, 0, len(data))
+	for name := range data {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		filePath := path.Join(prefix, name)
+		if dir, ok := data[name].(map[string]interface{}); ok {
+			if err := walkData(dir, filePath, fn); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := fn(filePath, data[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
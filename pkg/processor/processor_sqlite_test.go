@@ -0,0 +1,156 @@
+package processor
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessSQLite(t *testing.T) {
+	archive := newTarGz(t, map[string]string{
+		"repo-main/main.go":        "package main\n\nfunc main() {}\n",
+		"repo-main/docs/README.md": "# Docs\n\nServe the API.\n",
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(archive)
+	}))
+	defer server.Close()
+
+	tmpDir := t.TempDir()
+	p := New(&Config{
+		Repo:   "test-repo",
+		Url:    server.URL,
+		Dir:    "repo-main",
+		Output: tmpDir,
+		Format: FormatSQLite,
+	}, nil)
+
+	_, err := p.Process()
+	require.NoError(t, err)
+	assert.Equal(t, ".db", filepath.Ext(p.ReportPath()))
+
+	db, err := sql.Open("sqlite", p.ReportPath())
+	require.NoError(t, err)
+	defer db.Close()
+
+	var repo string
+	var files int
+	require.NoError(t, db.QueryRow(`SELECT repo, files FROM repos`).Scan(&repo, &files))
+	assert.Equal(t, "test-repo", repo)
+	assert.Equal(t, 2, files)
+
+	var dir, name, ext, hash, lang string
+	var size, tokens int
+	require.NoError(t, db.QueryRow(
+		`SELECT dir, name, ext, size, sha256, language, tokens FROM files WHERE path = ?`, "docs/README.md",
+	).Scan(&dir, &name, &ext, &size, &hash, &lang, &tokens))
+	assert.Equal(t, "docs", dir)
+	assert.Equal(t, "README.md", name)
+	assert.Equal(t, ".md", ext)
+	assert.Equal(t, 23, size)
+	assert.Equal(t, "Markdown", lang)
+	assert.Equal(t, 6, tokens)
+	assert.Len(t, hash, 64)
+
+	var match string
+	require.NoError(t, db.QueryRow(
+		`SELECT path FROM files_fts WHERE files_fts MATCH ?`, "api",
+	).Scan(&match))
+	assert.Equal(t, "docs/README.md", match)
+}
+
+func TestProcessSQLiteTreeOnly(t *testing.T) {
+	archive := newTarGz(t, map[string]string{
+		"repo-main/main.go": "package main\n",
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(archive)
+	}))
+	defer server.Close()
+
+	p := New(&Config{
+		Repo:     "test-repo",
+		Url:      server.URL,
+		Dir:      "repo-main",
+		Output:   t.TempDir(),
+		Format:   FormatSQLite,
+		TreeOnly: true,
+	}, nil)
+
+	_, err := p.Process()
+	require.NoError(t, err)
+
+	db, err := sql.Open("sqlite", p.ReportPath())
+	require.NoError(t, err)
+	defer db.Close()
+
+	var size int
+	var hash, content sql.NullString
+	require.NoError(t, db.QueryRow(`SELECT size, sha256, content FROM files`).Scan(&size, &hash, &content))
+	assert.Equal(t, 13, size)
+	assert.False(t, hash.Valid)
+	assert.False(t, content.Valid)
+}
+
+func TestProcessSQLiteAppend(t *testing.T) {
+	archives := map[string][]byte{
+		"/api": newTarGz(t, map[string]string{
+			"api-main/main.go":   "package main\n",
+			"api-main/server.go": "package main\n\n// Serve the API\n",
+		}),
+		"/web": newTarGz(t, map[string]string{
+			"web-main/index.js": "// Serve the site\n",
+		}),
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(archives[r.URL.Path])
+	}))
+	defer server.Close()
+
+	tmpDir := t.TempDir()
+	output := filepath.Join(tmpDir, "repos.db")
+	for _, repo := range []string{"api", "web"} {
+		p := New(&Config{
+			Repo:           repo,
+			Url:            server.URL + "/" + repo,
+			Dir:            repo + "-main",
+			Output:         tmpDir,
+			OutputTemplate: output,
+			Format:         FormatSQLite,
+			OnConflict:     OnConflictAppend,
+		}, nil)
+		_, err := p.Process()
+		require.NoError(t, err)
+		assert.Equal(t, output, p.ReportPath())
+	}
+
+	db, err := sql.Open("sqlite", output)
+	require.NoError(t, err)
+	defer db.Close()
+
+	var repos, files int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM repos`).Scan(&repos))
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM files`).Scan(&files))
+	assert.Equal(t, 2, repos)
+	assert.Equal(t, 3, files)
+
+	rows, err := db.Query(`SELECT repos.repo, files.path FROM files_fts
+		JOIN files ON files.rowid = files_fts.rowid
+		JOIN repos ON repos.id = files.repo_id
+		WHERE files_fts MATCH ? ORDER BY repos.repo`, "serve")
+	require.NoError(t, err)
+	defer rows.Close()
+	var matches []string
+	for rows.Next() {
+		var repo, path string
+		require.NoError(t, rows.Scan(&repo, &path))
+		matches = append(matches, repo+"/"+path)
+	}
+	require.NoError(t, rows.Err())
+	assert.Equal(t, []string{"api/server.go", "web/index.js"}, matches)
+}
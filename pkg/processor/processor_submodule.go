@@ -0,0 +1,372 @@
+package processor
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+const (
+	SubmodulesSkip    = "skip"
+	SubmodulesPointer = "pointer"
+	SubmodulesExpand  = "expand"
+
+	githubUrl = "https://github.com"
+	githubApi = "https://api.github.com"
+
+	errSubmoduleStatus = "submodule unexpected status code: %d"
+	errSubmoduleType   = "submodule path is a %s, not a submodule"
+)
+
+var submoduleModes = []string{SubmodulesSkip, SubmodulesPointer, SubmodulesExpand}
+
+// submoduleClient bounds the contents API requests resolving submodule
+// commits.
+var submoduleClient = &http.Client{Timeout: 10 * time.Second}
+
+// gitmodule is a submodule declared in .gitmodules.
+type gitmodule struct {
+	path string
+	url  string
+}
+
+// parseGitmodules returns the submodules declared in the content of a
+// .gitmodules file, in file order. Sections without a path or url are ignored.
+func parseGitmodules(content string) []gitmodule {
+	var modules []gitmodule
+	var current *gitmodule
+
+	flush := func() {
+		if current != nil && current.path != "" && current.url != "" {
+			modules = append(modules, *current)
+		}
+		current = nil
+	}
+
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		if line[0] == '[' {
+			flush()
+			if strings.HasPrefix(line, "[submodule ") {
+				current = &gitmodule{}
+			}
+			continue
+		}
+		if current == nil {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		value = strings.Trim(strings.TrimSpace(value), `"`)
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "path":
+			current.path = strings.Trim(path.Clean("/"+value), "/")
+		case "url":
+			current.url = value
+		}
+	}
+	flush()
+
+	return modules
+}
+
+// githubRepo returns the GitHub owner and repository a submodule URL points
+// to. Relative URLs are resolved against the repository of the processor.
+func (p *Processor) githubRepo(rawURL string) (owner, repo string, ok bool) {
+	rawURL = strings.TrimSuffix(strings.TrimSuffix(rawURL, "/"), ".git")
+
+	var repoPath string
+	switch {
+	case strings.HasPrefix(rawURL, "./") || strings.HasPrefix(rawURL, "../"):
+		repoPath = path.Join("/"+p.config.Owner+"/"+p.config.Repo, rawURL)
+	case strings.HasPrefix(rawURL, "git@github.com:"):
+		repoPath = strings.TrimPrefix(rawURL, "git@github.com:")
+	default:
+		u, err := url.Parse(rawURL)
+		if err != nil || (u.Host != "github.com" && u.Host != "www.github.com") {
+			return "", "", false
+		}
+		repoPath = u.Path
+	}
+
+	parts := strings.Split(strings.Trim(repoPath, "/"), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+// mapSubmodules resolves the commit of every submodule declared at the root
+// of the repository and, when expanding, grafts its files into the report.
+func (p *Processor) mapSubmodules() error {
+	if p.config.Submodules != SubmodulesPointer && p.config.Submodules != SubmodulesExpand {
+		return nil
+	}
+
+	for _, m := range parseGitmodules(p.gitmodules) {
+		sub := Submodule{Path: m.path, Url: m.url}
+		commit, err := p.submoduleCommit(m.path)
+		if err != nil {
+			p.update(fmt.Sprintf("unresolved submodule: %s (%v)", m.path, err))
+			p.submodules = append(p.submodules, sub)
+			continue
+		}
+		sub.Commit = commit
+
+		if p.config.Submodules == SubmodulesExpand && p.config.SubmoduleDepth > 0 {
+			if sub.Expanded, err = p.expandSubmodule(sub); err != nil {
+				return err
+			}
+		}
+		p.submodules = append(p.submodules, sub)
+	}
+	return nil
+}
+
+// submoduleCommit returns the commit a submodule is pinned at, which archives
+// leave out, through the contents API of the repository, authenticated with
+// the configured token if any.
+func (p *Processor) submoduleCommit(subPath string) (string, error) {
+	ref := p.commit
+	if ref == "" {
+		ref = p.config.Branch
+	}
+
+	segments := strings.Split(subPath, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	contentsUrl := fmt.Sprintf("%s/repos/%s/%s/contents/%s?ref=%s",
+		p.config.ApiUrl, p.config.Owner, p.config.Repo, strings.Join(segments, "/"), url.QueryEscape(ref))
+
+	req, err := http.NewRequest(http.MethodGet, contentsUrl, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if p.config.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.config.Token)
+	}
+
+	resp, err := submoduleClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("request error: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf(errSubmoduleStatus, resp.StatusCode)
+	}
+
+	// A directory is listed as an array, which is never a submodule
+	var content struct {
+		Type string `json:"type"`
+		Sha  string `json:"sha"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&content); err != nil {
+		return "", fmt.Errorf(errSubmoduleType, "directory")
+	}
+	if content.Type != "submodule" {
+		return "", fmt.Errorf(errSubmoduleType, content.Type)
+	}
+	return content.Sha, nil
+}
+
+// expandSubmodule maps the archive of a submodule at its pinned commit with
+// the same settings and grafts it into the report, reporting whether it was
+// expanded. Submodules outside the target directories or path selection, on
+// other hosts or whose archive cannot be read are left as pointers.
+func (p *Processor) expandSubmodule(sub Submodule) (bool, error) {
+	owner, repo, ok := p.githubRepo(sub.Url)
+	if !ok {
+		p.update(fmt.Sprintf("unsupported submodule: %s (%s)", sub.Path, sub.Url))
+		return false, nil
+	}
+
+	dir, dirs, prefix, ok := p.submoduleTarget(sub.Path, repo+"-"+sub.Commit)
+	if !ok {
+		return false, nil
+	}
+	paths, ok := p.submodulePaths(prefix)
+	if !ok {
+		return false, nil
+	}
+
+	config := *p.config
+	config.Owner = owner
+	config.Repo = repo
+	config.Branch = ""
+	config.Url = fmt.Sprintf("%s/%s/%s/archive/%s.tar.gz", p.config.GithubUrl, owner, repo, sub.Commit)
+	config.LFSUrl = fmt.Sprintf(githubLFS, owner, repo)
+	config.Dir = dir
+	config.Dirs = dirs
+	config.Paths = paths
+	config.Stdout = true
+	config.SubmoduleDepth--
+
+	child := New(&config, nil)
+	if _, err := child.Process(); err != nil {
+		p.update(fmt.Sprintf("unexpanded submodule: %s (%v)", sub.Path, err))
+		return false, nil
+	}
+	if err := p.graft(prefix, sub.Path, child); err != nil {
+		return false, err
+	}
+
+	p.update(fmt.Sprintf("expanded submodule: %s at %s", sub.Path, sub.Commit[:min(commitLen, len(sub.Commit))]))
+	return true, nil
+}
+
+// submoduleTarget maps the target directories onto the submodule at subPath,
+// whose archive has the top directory top. It returns the archive directories
+// of the submodule to map and the path of its files in the report, and false
+// when the submodule is outside every target directory.
+func (p *Processor) submoduleTarget(subPath, top string) (dir string, dirs []string, prefix string, ok bool) {
+	if len(p.config.Dirs) == 0 {
+		_, base, _ := strings.Cut(p.config.Dir, "/")
+		if base == "" {
+			return top, nil, subPath, true
+		}
+		if rest, ok := subdir(subPath, base); ok {
+			return top, nil, rest, true
+		}
+		if rest, ok := subdir(base, subPath); ok {
+			return path.Join(top, rest), nil, "", true
+		}
+		return "", nil, "", false
+	}
+
+	for _, d := range p.config.Dirs {
+		_, base, _ := strings.Cut(d, "/")
+		if _, ok := subdir(subPath, base); ok {
+			return top, nil, subPath, true
+		}
+		if rest, ok := subdir(base, subPath); ok {
+			dirs = append(dirs, path.Join(top, rest))
+		}
+	}
+	return top, dirs, subPath, len(dirs) > 0
+}
+
+// submodulePaths returns the path selection below prefix relative to it, and
+// false when paths are selected but none is below prefix.
+func (p *Processor) submodulePaths(prefix string) ([]string, bool) {
+	if len(p.config.Paths) == 0 {
+		return nil, true
+	}
+
+	var paths []string
+	for _, selected := range p.config.Paths {
+		if prefix == "" {
+			paths = append(paths, selected)
+		} else if rest, ok := subdir(selected, prefix); ok && rest != "" {
+			paths = append(paths, rest)
+		}
+	}
+	return paths, len(paths) > 0
+}
+
+// subdir returns name relative to dir and whether it is dir or below it.
+func subdir(name, dir string) (string, bool) {
+	if name == dir {
+		return "", true
+	}
+	if rest, ok := strings.CutPrefix(name, dir+"/"); ok {
+		return rest, true
+	}
+	return "", false
+}
+
+// graft places the files mapped by the processor of a submodule under prefix
+// in the report and merges its statistics, skipped files, redactions and
+// nested submodules.
+func (p *Processor) graft(prefix, subPath string, child *Processor) error {
+	join := func(name string) string {
+		return path.Join(prefix, name)
+	}
+
+	if len(child.data) > 0 {
+		current := p.data
+		if prefix != "" {
+			for _, part := range strings.Split(prefix, "/") {
+				if _, exists := current[part]; !exists {
+					current[part] = make(map[string]interface{})
+				}
+				var ok bool
+				if current, ok = current[part].(map[string]interface{}); !ok {
+					return fmt.Errorf("unexpected structure found on: %s", prefix)
+				}
+			}
+		}
+		for name, value := range child.data {
+			if _, exists := current[name]; exists {
+				return fmt.Errorf("unexpected structure found on: %s", join(name))
+			}
+			current[name] = value
+		}
+	}
+
+	for language, stats := range child.languages {
+		merged, ok := p.languages[language]
+		if !ok {
+			merged = &LanguageStats{Language: language}
+			p.languages[language] = merged
+		}
+		merged.Files += stats.Files
+		merged.Bytes += stats.Bytes
+		merged.Lines += stats.Lines
+		merged.Tokens += stats.Tokens
+	}
+
+	for _, s := range child.skipped {
+		// Files outside the target directories are relative to the submodule
+		if s.Reason == SkipOutsideDir {
+			s.Path = path.Join(subPath, s.Path)
+		} else {
+			s.Path = join(s.Path)
+		}
+		p.skipped = append(p.skipped, s)
+	}
+	for _, s := range child.dropped {
+		s.Path = join(s.Path)
+		p.dropped = append(p.dropped, s)
+	}
+	for _, r := range child.redactions {
+		r.Path = join(r.Path)
+		p.redactions = append(p.redactions, r)
+	}
+	for _, l := range child.lfs {
+		l.Path = join(l.Path)
+		p.lfs = append(p.lfs, l)
+	}
+	for name, lang := range child.fileLanguages {
+		if p.fileLanguages == nil {
+			p.fileLanguages = make(map[string]string)
+		}
+		p.fileLanguages[join(name)] = lang
+	}
+	for _, l := range child.links {
+		l.Path = join(l.Path)
+		p.links = append(p.links, l)
+	}
+	for _, s := range child.submodules {
+		s.Path = path.Join(subPath, s.Path)
+		p.submodules = append(p.submodules, s)
+	}
+
+	p.originalTokens += child.originalTokens
+	p.savedTokens += child.savedTokens
+	p.dirCount += child.dirCount
+	p.fileCount += child.fileCount
+	p.dataFileCount += child.dataFileCount
+	return nil
+}
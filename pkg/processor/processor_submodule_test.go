@@ -0,0 +1,245 @@
+package processor
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGitmodules(t *testing.T) {
+	content := `# submodules
+[submodule "core"]
+	path = libs/core
+	url = https://github.com/org/core.git
+[core]
+	path = ignored
+[submodule "docs"]
+	path = "docs/"
+	url = ../docs.git
+[submodule "incomplete"]
+	path = incomplete
+`
+	assert.Equal(t, []gitmodule{
+		{path: "libs/core", url: "https://github.com/org/core.git"},
+		{path: "docs", url: "../docs.git"},
+	}, parseGitmodules(content))
+	assert.Nil(t, parseGitmodules(""))
+}
+
+func TestGithubRepo(t *testing.T) {
+	p := New(&Config{Owner: "org", Repo: "app"}, nil)
+
+	tests := []struct {
+		url   string
+		owner string
+		repo  string
+		ok    bool
+	}{
+		{url: "https://github.com/org/core.git", owner: "org", repo: "core", ok: true},
+		{url: "https://github.com/org/core/", owner: "org", repo: "core", ok: true},
+		{url: "git@github.com:other/lib.git", owner: "other", repo: "lib", ok: true},
+		{url: "ssh://git@github.com/org/core.git", owner: "org", repo: "core", ok: true},
+		{url: "../util.git", owner: "org", repo: "util", ok: true},
+		{url: "../../other/util", owner: "other", repo: "util", ok: true},
+		{url: "https://gitlab.com/org/core.git", ok: false},
+		{url: "git@gitlab.com:org/core.git", ok: false},
+		{url: "https://github.com/org", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			owner, repo, ok := p.githubRepo(tt.url)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.owner, owner)
+			assert.Equal(t, tt.repo, repo)
+		})
+	}
+}
+
+func TestSubmoduleTarget(t *testing.T) {
+	tests := []struct {
+		name   string
+		dir    string
+		dirs   []string
+		sub    string
+		want   string
+		wants  []string
+		prefix string
+		ok     bool
+	}{
+		{name: "root", dir: "app-main", sub: "libs/core", want: "core-sha", prefix: "libs/core", ok: true},
+		{name: "within dir", dir: "app-main/libs", sub: "libs/core", want: "core-sha", prefix: "core", ok: true},
+		{name: "is dir", dir: "app-main/libs/core", sub: "libs/core", want: "core-sha", ok: true},
+		{name: "dir within", dir: "app-main/libs/core/src", sub: "libs/core", want: "core-sha/src", ok: true},
+		{name: "outside dir", dir: "app-main/api", sub: "libs/core", ok: false},
+		{name: "within dirs", dir: "app-main", dirs: []string{"app-main/api", "app-main/libs"}, sub: "libs/core", want: "core-sha", prefix: "libs/core", ok: true},
+		{name: "dirs within", dir: "app-main", dirs: []string{"app-main/api", "app-main/libs/core/src"}, sub: "libs/core", want: "core-sha", wants: []string{"core-sha/src"}, prefix: "libs/core", ok: true},
+		{name: "outside dirs", dir: "app-main", dirs: []string{"app-main/api", "app-main/web"}, sub: "libs/core", want: "core-sha", prefix: "libs/core", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := New(&Config{Dir: tt.dir, Dirs: tt.dirs}, nil)
+			dir, dirs, prefix, ok := p.submoduleTarget(tt.sub, "core-sha")
+			assert.Equal(t, tt.ok, ok)
+			if !ok {
+				return
+			}
+			assert.Equal(t, tt.want, dir)
+			assert.Equal(t, tt.wants, dirs)
+			assert.Equal(t, tt.prefix, prefix)
+		})
+	}
+}
+
+func TestProcessSubmodules(t *testing.T) {
+	const (
+		coreSha = "1111111111111111111111111111111111111111"
+		utilSha = "2222222222222222222222222222222222222222"
+		extSha  = "3333333333333333333333333333333333333333"
+	)
+	gitmodules := `[submodule "core"]
+	path = libs/core
+	url = https://github.com/org/core.git
+[submodule "ext"]
+	path = vendor/ext
+	url = git@gitlab.com:org/ext.git
+`
+	coreGitmodules := "[submodule \"util\"]\n\tpath = deps/util\n\turl = ../util.git\n"
+
+	archives := map[string][]byte{
+		"/archive": newTarGz(t, map[string]string{
+			"app-main/.gitmodules": gitmodules,
+			"app-main/main.go":     "package main\n",
+		}),
+		"/org/core/archive/" + coreSha + ".tar.gz": newTarGz(t, map[string]string{
+			"core-" + coreSha + "/.gitmodules": coreGitmodules,
+			"core-" + coreSha + "/core.go":     "package core\n",
+		}),
+		"/org/util/archive/" + utilSha + ".tar.gz": newTarGz(t, map[string]string{
+			"util-" + utilSha + "/util.go": "package util\n",
+		}),
+	}
+	contents := map[string]string{
+		"/repos/org/app/contents/libs/core":  coreSha,
+		"/repos/org/app/contents/vendor/ext": extSha,
+		"/repos/org/core/contents/deps/util": utilSha,
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if archive, ok := archives[r.URL.Path]; ok {
+			w.Write(archive)
+			return
+		}
+		if sha, ok := contents[r.URL.Path]; ok {
+			fmt.Fprintf(w, `{"type":"submodule","sha":%q}`, sha)
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	newProcessor := func(mode string, depth int, dir string) *Processor {
+		return New(&Config{
+			Owner:          "org",
+			Repo:           "app",
+			Branch:         "main",
+			Url:            server.URL + "/archive",
+			GithubUrl:      server.URL,
+			ApiUrl:         server.URL,
+			Dir:            dir,
+			Stdout:         true,
+			Submodules:     mode,
+			SubmoduleDepth: depth,
+		}, nil)
+	}
+
+	t.Run("skip", func(t *testing.T) {
+		p := newProcessor(SubmodulesSkip, 1, "app-main")
+		got, err := p.Process()
+		require.NoError(t, err)
+		assert.Equal(t, RepositoryData{".gitmodules": gitmodules, "main.go": "package main\n"}, got)
+		assert.Empty(t, p.Manifest().Submodules)
+	})
+
+	t.Run("pointer", func(t *testing.T) {
+		p := newProcessor(SubmodulesPointer, 1, "app-main")
+		got, err := p.Process()
+		require.NoError(t, err)
+		assert.Len(t, got, 2)
+		assert.Equal(t, []Submodule{
+			{Path: "libs/core", Url: "https://github.com/org/core.git", Commit: coreSha},
+			{Path: "vendor/ext", Url: "git@gitlab.com:org/ext.git", Commit: extSha},
+		}, p.Manifest().Submodules)
+	})
+
+	t.Run("expand", func(t *testing.T) {
+		p := newProcessor(SubmodulesExpand, 1, "app-main")
+		got, err := p.Process()
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{
+			"core": map[string]interface{}{".gitmodules": coreGitmodules, "core.go": "package core\n"},
+		}, map[string]interface{}(got["libs"].(map[string]interface{})))
+		assert.Equal(t, []Submodule{
+			{Path: "libs/core", Url: "https://github.com/org/core.git", Commit: coreSha, Expanded: true},
+			{Path: "libs/core/deps/util", Url: "../util.git", Commit: utilSha},
+			{Path: "vendor/ext", Url: "git@gitlab.com:org/ext.git", Commit: extSha},
+		}, p.Manifest().Submodules)
+		assert.Equal(t, 4, p.Stats().Files)
+	})
+
+	t.Run("expand nested", func(t *testing.T) {
+		p := newProcessor(SubmodulesExpand, 2, "app-main")
+		got, err := p.Process()
+		require.NoError(t, err)
+		core := got["libs"].(map[string]interface{})["core"].(map[string]interface{})
+		assert.Equal(t, "package util\n", core["deps"].(map[string]interface{})["util"].(map[string]interface{})["util.go"])
+		assert.True(t, p.Manifest().Submodules[1].Expanded)
+		assert.Equal(t, 5, p.Stats().Files)
+	})
+
+	t.Run("expand depth zero", func(t *testing.T) {
+		p := newProcessor(SubmodulesExpand, 0, "app-main")
+		got, err := p.Process()
+		require.NoError(t, err)
+		assert.Len(t, got, 2)
+		assert.Equal(t, []Submodule{
+			{Path: "libs/core", Url: "https://github.com/org/core.git", Commit: coreSha},
+			{Path: "vendor/ext", Url: "git@gitlab.com:org/ext.git", Commit: extSha},
+		}, p.Manifest().Submodules)
+	})
+
+	t.Run("dir within submodule", func(t *testing.T) {
+		p := newProcessor(SubmodulesExpand, 1, "app-main/libs/core")
+		got, err := p.Process()
+		require.NoError(t, err)
+		assert.Equal(t, RepositoryData{".gitmodules": coreGitmodules, "core.go": "package core\n"}, got)
+	})
+}
+
+func TestSubmoduleCommitToken(t *testing.T) {
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		fmt.Fprint(w, `{"type":"submodule","sha":"abc"}`)
+	}))
+	defer server.Close()
+
+	for _, tt := range []struct {
+		token string
+		want  string
+	}{
+		{token: "", want: ""},
+		{token: "secret", want: "Bearer secret"},
+	} {
+		p := New(&Config{Owner: "org", Repo: "app", Branch: "main", ApiUrl: server.URL, Token: tt.token}, nil)
+		commit, err := p.submoduleCommit("libs/core")
+		require.NoError(t, err)
+		assert.Equal(t, "abc", commit)
+		assert.Equal(t, tt.want, auth)
+	}
+}
@@ -5,42 +5,48 @@ import (
 	"bytes"
 	"compress/gzip"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"regexp"
 	"testing"
-	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
-func TestProcess(t *testing.T) {
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		var buf bytes.Buffer
-		gw := gzip.NewWriter(&buf)
-		tw := tar.NewWriter(gw)
+func newTarGz(tb testing.TB, files map[string]string) []byte {
+	tb.Helper()
 
-		files := map[string]string{
-			"repo-main/file1.go":  "package main",
-			"repo-main/file2.txt": "hello world",
-		}
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
 
-		for name, content := range files {
-			hdr := &tar.Header{
-				Name: name,
-				Mode: 0600,
-				Size: int64(len(content)),
-			}
-			require.NoError(t, tw.WriteHeader(hdr))
-			_, err := tw.Write([]byte(content))
-			require.NoError(t, err)
+	for name, content := range files {
+		hdr := &tar.Header{
+			Name: name,
+			Mode: 0600,
+			Size: int64(len(content)),
 		}
+		require.NoError(tb, tw.WriteHeader(hdr))
+		_, err := tw.Write([]byte(content))
+		require.NoError(tb, err)
+	}
+
+	require.NoError(tb, tw.Close())
+	require.NoError(tb, gw.Close())
+	return buf.Bytes()
+}
 
-		require.NoError(t, tw.Close())
-		require.NoError(t, gw.Close())
-		w.Write(buf.Bytes())
+func TestProcess(t *testing.T) {
+	archive := newTarGz(t, map[string]string{
+		"repo-main/file1.go":  "package main",
+		"repo-main/file2.txt": "hello world",
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(archive)
 	}))
 	defer server.Close()
 
@@ -65,7 +71,7 @@ func TestProcess(t *testing.T) {
 				Include: []string{".go"},
 			},
 			wantErr:     false,
-			wantUpdates: 5, // download + mapping + 2 stats + save updates
+			wantUpdates: 6, // download + mapping + 2 stats + manifest + save updates
 			wantFiles:   []string{"file1.go"},
 		},
 		{
@@ -77,7 +83,7 @@ func TestProcess(t *testing.T) {
 				Output: tmpDir,
 			},
 			wantErr:     false,
-			wantUpdates: 6, // download + 2 mappings + 2 stats + save updates
+			wantUpdates: 7, // download + 2 mappings + 2 stats + manifest + save updates
 			wantFiles:   []string{"file1.go", "file2.txt"},
 		},
 	}
@@ -98,7 +104,7 @@ func TestProcess(t *testing.T) {
 			}()
 
 			// Run processor
-			_, err := processor.Process(time.Millisecond)
+			_, err := processor.Process()
 			if tt.wantErr {
 				assert.Error(t, err)
 				return
@@ -108,9 +114,14 @@ func TestProcess(t *testing.T) {
 
 			assert.Equal(t, tt.wantUpdates, len(updates))
 
-			files, err := filepath.Glob(filepath.Join(tmpDir, "*.json"))
+			files, err := filepath.Glob(filepath.Join(tmpDir, "*[0-9].json"))
 			require.NoError(t, err)
 			assert.Equal(t, 1, len(files))
+			assert.Equal(t, files[0], processor.ReportPath())
+
+			manifests, err := filepath.Glob(filepath.Join(tmpDir, "*.manifest.json"))
+			require.NoError(t, err)
+			assert.Equal(t, 1, len(manifests))
 
 			content, err := os.ReadFile(files[0])
 			require.NoError(t, err)
@@ -125,3 +136,344 @@ func TestProcess(t *testing.T) {
 		})
 	}
 }
+
+func TestProcessStats(t *testing.T) {
+	archive := newTarGz(t, map[string]string{
+		"repo-main/main.go":        "package main\n\nfunc main() {}\n",
+		"repo-main/util.go":        "package main\n",
+		"repo-main/web/app.ts":     "export const app = 1\n",
+		"repo-main/bin/deploy":     "#!/usr/bin/env bash\necho deploy\n",
+		"repo-main/docs/README.md": "# Docs\n",
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(archive)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		langs     []string
+		wantLangs []string
+		wantFiles int
+	}{
+		{
+			name:      "all languages",
+			wantLangs: []string{"Go", "Shell", "TypeScript", "Markdown"},
+			wantFiles: 5,
+		},
+		{
+			name:      "language filter",
+			langs:     []string{"Go", "Shell"},
+			wantLangs: []string{"Go", "Shell"},
+			wantFiles: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := New(&Config{
+				Url:    server.URL,
+				Dir:    "repo-main",
+				Langs:  tt.langs,
+				Stdout: true,
+			}, nil)
+			_, err := p.Process()
+			require.NoError(t, err)
+
+			stats := p.Stats()
+			var gotLangs []string
+			for _, l := range stats.Languages {
+				gotLangs = append(gotLangs, l.Language)
+			}
+			assert.Equal(t, tt.wantLangs, gotLangs)
+			assert.Equal(t, tt.wantFiles, stats.Files)
+
+			goStats := stats.Languages[0]
+			assert.Equal(t, LanguageStats{Language: "Go", Files: 2, Bytes: 42, Lines: 4, Tokens: 12}, goStats)
+		})
+	}
+}
+
+func TestProcessTreeOnly(t *testing.T) {
+	archive := newTarGz(t, map[string]string{
+		"repo-main/main.go":        "package main\n",
+		"repo-main/api/server.go":  "package api\n",
+		"repo-main/docs/README.md": "# Docs\n",
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(archive)
+	}))
+	defer server.Close()
+
+	tmpDir := t.TempDir()
+	p := New(&Config{
+		Repo:       "test-repo",
+		Url:        server.URL,
+		Dir:        "repo-main",
+		Output:     tmpDir,
+		Exclude:    []string{".md"},
+		TreeOnly:   true,
+		TreeFormat: TreeFormatASCII,
+	}, nil)
+
+	data, err := p.Process()
+	require.NoError(t, err)
+	assert.Equal(t, RepositoryData{
+		"main.go": int64(13),
+		"api": map[string]interface{}{
+			"server.go": int64(12),
+		},
+	}, data)
+
+	files, err := filepath.Glob(filepath.Join(tmpDir, "*.txt"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	content, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	assert.Equal(t, ".\n├── api/\n│   └── server.go (12 B)\n└── main.go (13 B)\n", string(content))
+}
+
+func TestProcessSkipped(t *testing.T) {
+	archive := newTarGz(t, map[string]string{
+		"repo-main/README.md":        "# Readme\n",
+		"repo-main/src/main.go":      "package main\n",
+		"repo-main/src/main_test.go": "package main\n",
+		"repo-main/src/notes.txt":    "notes\n",
+		"repo-main/src/big.go":       "package main\n\nvar big = \"0123456789\"\n",
+		"repo-main/src/data.bin":     "\x00\x01\x02",
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(archive)
+	}))
+	defer server.Close()
+
+	tmpDir := t.TempDir()
+	p := New(&Config{
+		Repo:        "test-repo",
+		Url:         server.URL,
+		Dir:         "repo-main",
+		Dirs:        []string{"repo-main/src"},
+		Output:      tmpDir,
+		Include:     []string{".go", ".bin"},
+		Exclude:     []string{"_test.go"},
+		MaxSize:     20,
+		ListSkipped: true,
+	}, nil)
+
+	data, err := p.Process()
+	require.NoError(t, err)
+	assert.Equal(t, RepositoryData{
+		"src": map[string]interface{}{
+			"main.go": "package main\n",
+		},
+	}, data)
+
+	wantSkipped := []Skip{
+		{Path: "README.md", Reason: SkipOutsideDir},
+		{Path: "src/big.go", Reason: SkipTooLarge},
+		{Path: "src/data.bin", Reason: SkipBinary},
+		{Path: "src/main_test.go", Reason: SkipExcluded, Rule: "_test.go"},
+		{Path: "src/notes.txt", Reason: SkipNotIncluded},
+	}
+	assert.Equal(t, wantSkipped, p.Skipped())
+	assert.Equal(t, map[string]int{
+		SkipOutsideDir:  1,
+		SkipTooLarge:    1,
+		SkipBinary:      1,
+		SkipExcluded:    1,
+		SkipNotIncluded: 1,
+	}, p.Stats().Skipped)
+
+	manifests, err := filepath.Glob(filepath.Join(tmpDir, "*.manifest.json"))
+	require.NoError(t, err)
+	require.Len(t, manifests, 1)
+
+	content, err := os.ReadFile(manifests[0])
+	require.NoError(t, err)
+	var manifest Manifest
+	require.NoError(t, json.Unmarshal(content, &manifest))
+	assert.Equal(t, wantSkipped, manifest.Skipped)
+}
+
+func TestProcessContains(t *testing.T) {
+	archive := newTarGz(t, map[string]string{
+		"repo-main/main.go": "package main\n\nimport \"net/http\"\n",
+		"repo-main/util.go": "package main\n",
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(archive)
+	}))
+	defer server.Close()
+
+	for _, treeOnly := range []bool{false, true} {
+		p := New(&Config{
+			Url:      server.URL,
+			Dir:      "repo-main",
+			Contains: regexp.MustCompile(`"net/http"`),
+			TreeOnly: treeOnly,
+			Stdout:   true,
+		}, nil)
+		data, err := p.Process()
+		require.NoError(t, err)
+
+		assert.Contains(t, data, "main.go")
+		assert.NotContains(t, data, "util.go")
+		assert.Equal(t, []Skip{{Path: "util.go", Reason: SkipNoMatch, Rule: `"net/http"`}}, p.Skipped())
+	}
+}
+
+func TestProcessDirs(t *testing.T) {
+	archive := newTarGz(t, map[string]string{
+		"repo-main/main.go":             "package main",
+		"repo-main/api/server.go":       "package api",
+		"repo-main/apiclient/client.go": "package apiclient",
+		"repo-main/pkg/auth/auth.go":    "package auth",
+		"repo-main/pkg/cache/cache.go":  "package cache",
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(archive)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		config *Config
+		want   RepositoryData
+		name   string
+	}{
+		{
+			name: "single directory",
+			config: &Config{
+				Url:    server.URL,
+				Dir:    "repo-main/api",
+				Stdout: true,
+			},
+			want: RepositoryData{
+				"server.go": "package api",
+			},
+		},
+		{
+			name: "multiple directories",
+			config: &Config{
+				Url:    server.URL,
+				Dir:    "repo-main",
+				Dirs:   []string{"repo-main/api", "repo-main/pkg/auth"},
+				Stdout: true,
+			},
+			want: RepositoryData{
+				"api": map[string]interface{}{
+					"server.go": "package api",
+				},
+				"pkg": map[string]interface{}{
+					"auth": map[string]interface{}{
+						"auth.go": "package auth",
+					},
+				},
+			},
+		},
+		{
+			name: "selected paths",
+			config: &Config{
+				Url:    server.URL,
+				Dir:    "repo-main",
+				Paths:  []string{"main.go", "pkg/cache/cache.go", "missing.go"},
+				Stdout: true,
+			},
+			want: RepositoryData{
+				"main.go": "package main",
+				"pkg": map[string]interface{}{
+					"cache": map[string]interface{}{
+						"cache.go": "package cache",
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := New(tt.config, nil).Process()
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, data)
+		})
+	}
+}
+
+func TestProcessRedaction(t *testing.T) {
+	archive := newTarGz(t, map[string]string{
+		"repo-main/main.go":   "package main",
+		"repo-main/.env":      "AWS_ACCESS_KEY_ID=" + awsKeyID + "\n",
+		"repo-main/deploy.sh": "#!/bin/sh\n\nexport GITHUB_TOKEN=" + githubToken + "\n",
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(archive)
+	}))
+	defer server.Close()
+
+	rules, err := createRedactRules(nil)
+	require.NoError(t, err)
+
+	tmpDir := t.TempDir()
+	p := New(&Config{
+		Repo:        "test-repo",
+		Url:         server.URL,
+		Dir:         "repo-main",
+		Output:      tmpDir,
+		Redact:      RedactDropFile,
+		RedactRules: rules,
+	}, nil)
+
+	data, err := p.Process()
+	require.NoError(t, err)
+
+	assert.Contains(t, data, "main.go")
+	assert.NotContains(t, data, ".env")
+	assert.NotContains(t, data, "deploy.sh")
+	assert.ElementsMatch(t, []Redaction{
+		{Path: ".env", Line: 1, Rule: "aws-access-key-id"},
+		{Path: "deploy.sh", Line: 3, Rule: "github-token"},
+	}, p.Redactions())
+
+	reports, err := filepath.Glob(filepath.Join(tmpDir, "*.redactions.json"))
+	require.NoError(t, err)
+	require.Len(t, reports, 1)
+
+	content, err := os.ReadFile(reports[0])
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), awsKeyID)
+	assert.NotContains(t, string(content), githubToken)
+}
+
+func BenchmarkProcess(b *testing.B) {
+	files := make(map[string]string, 50000)
+	for i := 0; i < 50000; i++ {
+		name := fmt.Sprintf("repo-main/pkg%03d/file%05d.go", i%500, i)
+		files[name] = fmt.Sprintf("package pkg%03d\n\nfunc F%05d() {}\n", i%500, i)
+	}
+	archive := newTarGz(b, files)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(archive)
+	}))
+	defer server.Close()
+
+	config := &Config{
+		Repo:   "test-repo",
+		Url:    server.URL,
+		Dir:    "repo-main",
+		Stdout: true,
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		updateCh := make(chan Update)
+		go func() {
+			for range updateCh {
+			}
+		}()
+
+		if _, err := New(config, updateCh).Process(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package processor
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	TreeFormatJSON  = "json"
+	TreeFormatASCII = "ascii"
+)
+
+var treeFormats = []string{TreeFormatJSON, TreeFormatASCII}
+
+// RenderTree renders repository data as a tree(1) style listing. File sizes
+// are shown for tree-only data, where files map to their size.
+func RenderTree(data RepositoryData) string {
+	var b strings.Builder
+	b.WriteString(".\n")
+	renderTree(&b, data, "")
+	return b.String()
+}
+
+func renderTree(b *strings.Builder, node map[string]interface{}, prefix string) {
+	names := make([]string, 0, len(node))
+	for name := range node {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for i, name := range names {
+		branch, indent := "├── ", "│   "
+		if i == len(names)-1 {
+			branch, indent = "└── ", "    "
+		}
+
+		switch v := node[name].(type) {
+		case map[string]interface{}:
+			fmt.Fprintf(b, "%s%s%s/\n", prefix, branch, name)
+			renderTree(b, v, prefix+indent)
+		case RepositoryData:
+			fmt.Fprintf(b, "%s%s%s/\n", prefix, branch, name)
+			renderTree(b, v, prefix+indent)
+		case int64:
+			fmt.Fprintf(b, "%s%s%s (%d B)\n", prefix, branch, name, v)
+		default:
+			fmt.Fprintf(b, "%s%s%s\n", prefix, branch, name)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package processor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderTree(t *testing.T) {
+	data := RepositoryData{
+		"main.go":   int64(120),
+		"README.md": int64(42),
+		"pkg": map[string]interface{}{
+			"api": map[string]interface{}{
+				"server.go": int64(2048),
+			},
+			"util.go": int64(64),
+		},
+	}
+
+	want := `.
+├── README.md (42 B)
+├── main.go (120 B)
+└── pkg/
+    ├── api/
+    │   └── server.go (2048 B)
+    └── util.go (64 B)
+`
+
+	assert.Equal(t, want, RenderTree(data))
+}
@@ -6,6 +6,12 @@ func (p *Processor) update(description string) {
 	}
 }
 
+func (p *Processor) updateStats(description string, stats *Stats) {
+	if p.ch != nil {
+		p.ch <- Update{Description: description, Stats: stats}
+	}
+}
+
 func (p *Processor) updateError(err error) {
 	if p.ch != nil {
 		p.ch <- Update{Err: err}
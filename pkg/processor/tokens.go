@@ -0,0 +1,28 @@
+package processor
+
+import "strings"
+
+// EstimateTokens approximates the number of LLM tokens in s, using the
+// common rule of thumb of four bytes per token.
+func EstimateTokens(s string) int {
+	return EstimateSizeTokens(int64(len(s)))
+}
+
+// EstimateSizeTokens approximates the number of LLM tokens in a file of size
+// bytes, for files whose content is not read.
+func EstimateSizeTokens(size int64) int {
+	return (int(size) + 3) / 4
+}
+
+// CountLines returns the number of lines in s, counting a last line without
+// a trailing newline.
+func CountLines(s string) int {
+	if s == "" {
+		return 0
+	}
+	lines := strings.Count(s, "\n")
+	if !strings.HasSuffix(s, "\n") {
+		lines++
+	}
+	return lines
+}
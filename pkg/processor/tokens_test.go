@@ -0,0 +1,21 @@
+package processor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCountLines(t *testing.T) {
+	assert.Equal(t, 0, CountLines(""))
+	assert.Equal(t, 1, CountLines("package main"))
+	assert.Equal(t, 1, CountLines("package main\n"))
+	assert.Equal(t, 3, CountLines("package main\n\nfunc main() {}"))
+}
+
+func TestEstimateTokens(t *testing.T) {
+	assert.Equal(t, 0, EstimateTokens(""))
+	assert.Equal(t, 1, EstimateTokens("go"))
+	assert.Equal(t, 4, EstimateTokens("package main\n"))
+	assert.Equal(t, EstimateTokens("package main\n"), EstimateSizeTokens(13))
+}
@@ -1,27 +1,238 @@
 package processor
 
+import "regexp"
+
 type RepositoryData map[string]interface{}
 
+// Options are the user-facing settings resolved into a Config by NewConfig.
+type Options struct {
+	Slug           string
+	Branch         string
+	Output         string
+	Mode           string
+	Redact         string
+	TreeFormat     string
+	Format         string
+	OnConflict     string
+	Compress       string
+	LFS            string
+	Submodules     string
+	Contains       string
+	Token          string
+	Dirs           []string
+	Full           []string
+	Langs          []string
+	Include        []string
+	Exclude        []string
+	Paths          []string
+	RedactPatterns []string
+	Jobs           int
+	MaxSize        int
+	SubmoduleDepth int
+	Minify         bool
+	Stdout         bool
+	TreeOnly       bool
+	ListSkipped    bool
+	Deterministic  bool
+	FollowSymlinks bool
+	Gitignore      bool
+	MkDir          bool
+}
+
 type Config struct {
-	Repo    string
-	Url     string
-	Dir     string
-	Output  string
-	Include []string
-	Exclude []string
-	Stdout  bool
+	Owner          string
+	Repo           string
+	Branch         string
+	Url            string
+	Dir            string
+	Output         string
+	OutputTemplate string
+	OnConflict     string
+	Compress       string
+	LFS            string
+	LFSUrl         string
+	Submodules     string
+	GithubUrl      string
+	ApiUrl         string
+	Mode           string
+	Redact         string
+	TreeFormat     string
+	Format         string
+	Token          string
+	Dirs           []string
+	Full           []string
+	Langs          []string
+	Include        []string
+	Exclude        []string
+	Paths          []string
+	RedactRules    []RedactRule
+	Contains       *regexp.Regexp
+	Jobs           int
+	MaxSize        int
+	SubmoduleDepth int
+	Minify         bool
+	Stdout         bool
+	TreeOnly       bool
+	ListSkipped    bool
+	Deterministic  bool
+	FollowSymlinks bool
+	Gitignore      bool
+	MkDir          bool
 }
 
 type Update struct {
 	Err         error
+	Stats       *Stats
 	Description string
 }
 
+// LanguageStats summarizes the mapped files of a single language.
+type LanguageStats struct {
+	Language string `json:"language"`
+	Files    int    `json:"files"`
+	Bytes    int    `json:"bytes"`
+	Lines    int    `json:"lines"`
+	Tokens   int    `json:"tokens"`
+}
+
+// Stats summarizes the mapped files, with languages ordered by size, and
+// counts the skipped files per reason.
+type Stats struct {
+	Languages []LanguageStats `json:"languages"`
+	Skipped   map[string]int  `json:"skipped,omitempty"`
+	Files     int             `json:"files"`
+	Bytes     int             `json:"bytes"`
+	Lines     int             `json:"lines"`
+	Tokens    int             `json:"tokens"`
+}
+
+// Skip records a file left out of the report and why. Rule is the include,
+// exclude, language or content rule responsible, if any.
+type Skip struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+	Rule   string `json:"rule,omitempty"`
+}
+
+// LFSFile is a Git LFS pointer found in the archive. Resolved reports
+// whether its object was fetched and mapped in place of the pointer.
+type LFSFile struct {
+	Path     string `json:"path"`
+	Oid      string `json:"oid"`
+	Size     int64  `json:"size"`
+	Resolved bool   `json:"resolved"`
+}
+
+// Link is a symbolic or hard link of the repository, with Target as recorded
+// in the archive. Followed reports whether the content of its target was
+// mapped at its path.
+type Link struct {
+	Path     string `json:"path"`
+	Type     string `json:"type"`
+	Target   string `json:"target"`
+	Followed bool   `json:"followed"`
+}
+
+// Submodule is a Git submodule of the repository. Commit is empty when the
+// pinned commit could not be resolved, and Expanded reports whether its
+// files were mapped into the report.
+type Submodule struct {
+	Path     string `json:"path"`
+	Url      string `json:"url"`
+	Commit   string `json:"commit,omitempty"`
+	Expanded bool   `json:"expanded"`
+}
+
+// Manifest describes a generated report.
+type Manifest struct {
+	Repo       string      `json:"repo"`
+	Url        string      `json:"url"`
+	Commit     string      `json:"commit,omitempty"`
+	Dir        string      `json:"dir"`
+	Dirs       []string    `json:"dirs,omitempty"`
+	Skipped    []Skip      `json:"skipped,omitempty"`
+	LFS        []LFSFile   `json:"lfs,omitempty"`
+	Links      []Link      `json:"links,omitempty"`
+	Submodules []Submodule `json:"submodules,omitempty"`
+	Stats      Stats       `json:"stats"`
+}
+
+// RedactRule detects a kind of secret. When Pattern has a capture group only
+// the first group is treated as the secret. Candidates whose Shannon entropy
+// is below MinEntropy are ignored.
+type RedactRule struct {
+	Pattern    *regexp.Regexp
+	Name       string
+	MinEntropy float64
+}
+
+// Redaction records where a secret was found, without the secret itself.
+type Redaction struct {
+	Path string `json:"path"`
+	Rule string `json:"rule"`
+	Line int    `json:"line"`
+}
+
 type Processor struct {
-	config        *Config
-	data          RepositoryData
-	ch            chan<- Update
-	dirCount      int
-	fileCount     int
-	dataFileCount int
+	config         *Config
+	data           RepositoryData
+	ch             chan<- Update
+	transforms     []transform
+	redactions     []Redaction
+	skipped        []Skip
+	dropped        []Skip
+	lfs            []LFSFile
+	submodules     []Submodule
+	links          []Link
+	fileLanguages  map[string]string
+	languages      map[string]*LanguageStats
+	paths          map[string]bool
+	linkTargets    map[string]string
+	ignoreRules    []ignoreRule
+	ignorePending  []pendingFile
+	ignoreSettled  bool
+	lfsPending     []*File
+	commit         string
+	gitmodules     string
+	reportPath     string
+	originalTokens int
+	savedTokens    int
+	dirCount       int
+	fileCount      int
+	dataFileCount  int
+}
+
+// InspectedFile is a file listed by an inspection.
+type InspectedFile struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	Tokens int    `json:"tokens"`
+}
+
+// RuleMatch lists the files a single include, exclude or lang rule matched
+// or dropped.
+type RuleMatch struct {
+	Kind    string   `json:"kind"`
+	Rule    string   `json:"rule"`
+	Matched []string `json:"matched"`
+	Dropped []string `json:"dropped"`
+}
+
+// Inspection previews what a mapping would select, from archive headers only.
+// Unmatched lists the files no include, lang or path selection matched, and
+// Skipped the files left out by size or .gitignore, per skip reason.
+type Inspection struct {
+	Repo          string              `json:"repo"`
+	Url           string              `json:"url"`
+	Dir           string              `json:"dir"`
+	Largest       []InspectedFile     `json:"largest"`
+	Rules         []RuleMatch         `json:"rules"`
+	Unmatched     []string            `json:"unmatched"`
+	Skipped       map[string][]string `json:"skipped,omitempty"`
+	Dirs          int                 `json:"dirs"`
+	Files         int                 `json:"files"`
+	Bytes         int64               `json:"bytes"`
+	Selected      int                 `json:"selected"`
+	SelectedBytes int64               `json:"selectedBytes"`
+	Tokens        int                 `json:"tokens"`
 }
@@ -0,0 +1,366 @@
+// Package query filters and projects the files of octomap reports.
+//
+// A query selects fields and filters files:
+//
+//	[select field, ...] [where] expression
+//
+// Expressions combine predicates of the form field op value with and, or, not
+// and parentheses. String fields support = and != for equality, ~ for globs,
+// where ** spans directories, and =~ for regular expressions. Numeric fields
+// support = != < <= > >= and values with a k or m suffix, such as 10k.
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	invalidQuery    = "invalid query, %s at %q\n"
+	invalidField    = "invalid query, unknown field %q, must be one of %s\n"
+	invalidOperator = "invalid query, operator %q cannot be used with %s\n"
+	invalidNumber   = "invalid query, expected a number for %s, received %q\n"
+	invalidRegexp   = "invalid query, bad regular expression %q\n%v\n"
+)
+
+// Fields that can be selected and filtered on.
+var (
+	stringFields = []string{"path", "dir", "name", "ext", "language", "content"}
+	numberFields = []string{"size", "tokens", "lines"}
+	Fields       = append(slices.Clone(stringFields), numberFields...)
+)
+
+// Query is a parsed query. Fields lists the projection, path by default.
+type Query struct {
+	match  func(f *File) bool
+	Fields []string
+}
+
+// Match reports whether a file satisfies the query filter.
+func (q *Query) Match(f *File) bool {
+	return q.match == nil || q.match(f)
+}
+
+// Parse parses a query expression.
+func Parse(expr string) (*Query, error) {
+	tokens, err := lex(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	q := &Query{Fields: []string{"path"}}
+
+	if p.keyword("select") {
+		q.Fields = nil
+		for {
+			field := p.next()
+			if err := validateField(field.value); err != nil {
+				return nil, err
+			}
+			q.Fields = append(q.Fields, field.value)
+			if !p.punct(",") {
+				break
+			}
+		}
+	}
+
+	p.keyword("where")
+	if p.done() {
+		return q, nil
+	}
+
+	q.match, err = p.or()
+	if err != nil {
+		return nil, err
+	}
+	if !p.done() {
+		return nil, fmt.Errorf(invalidQuery, "unexpected token", p.peek().value)
+	}
+	return q, nil
+}
+
+func validateField(field string) error {
+	if !slices.Contains(Fields, field) {
+		return fmt.Errorf(invalidField, field, strings.Join(Fields, ", "))
+	}
+	return nil
+}
+
+type tokenKind int
+
+const (
+	endToken tokenKind = iota
+	wordToken
+	stringToken
+	operatorToken
+	punctToken
+)
+
+type token struct {
+	value string
+	kind  tokenKind
+}
+
+var operators = []string{"=~", "!=", "<=", ">=", "=", "<", ">", "~"}
+
+func lex(expr string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case c == '(' || c == ')' || c == ',':
+			tokens = append(tokens, token{value: string(c), kind: punctToken})
+			i++
+		case c == '"' || c == '\'':
+			end := strings.IndexByte(expr[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf(invalidQuery, "unterminated string", expr[i:])
+			}
+			tokens = append(tokens, token{value: expr[i+1 : i+1+end], kind: stringToken})
+			i += end + 2
+		case strings.IndexByte("=!<>~", c) >= 0:
+			op := ""
+			for _, candidate := range operators {
+				if strings.HasPrefix(expr[i:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf(invalidQuery, "unknown operator", expr[i:])
+			}
+			tokens = append(tokens, token{value: op, kind: operatorToken})
+			i += len(op)
+		default:
+			end := i
+			for end < len(expr) && strings.IndexByte(" \t\n(),=!<>~\"'", expr[end]) < 0 {
+				end++
+			}
+			tokens = append(tokens, token{value: expr[i:end], kind: wordToken})
+			i = end
+		}
+	}
+	return tokens, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) done() bool { return p.pos >= len(p.tokens) }
+
+func (p *parser) peek() token {
+	if p.done() {
+		return token{}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+// keyword consumes the next token if it is the given keyword.
+func (p *parser) keyword(word string) bool {
+	t := p.peek()
+	if t.kind == wordToken && strings.EqualFold(t.value, word) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// punct consumes the next token if it is the given punctuation.
+func (p *parser) punct(value string) bool {
+	t := p.peek()
+	if t.kind == punctToken && t.value == value {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+type matcher = func(f *File) bool
+
+func (p *parser) or() (matcher, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("or") {
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(f *File) bool { return l(f) || right(f) }
+	}
+	return left, nil
+}
+
+func (p *parser) and() (matcher, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("and") {
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(f *File) bool { return l(f) && right(f) }
+	}
+	return left, nil
+}
+
+func (p *parser) unary() (matcher, error) {
+	if p.keyword("not") {
+		m, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return func(f *File) bool { return !m(f) }, nil
+	}
+	if p.punct("(") {
+		m, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if !p.punct(")") {
+			return nil, fmt.Errorf(invalidQuery, "expected )", p.peek().value)
+		}
+		return m, nil
+	}
+	return p.predicate()
+}
+
+func (p *parser) predicate() (matcher, error) {
+	field := p.next()
+	if field.kind != wordToken {
+		return nil, fmt.Errorf(invalidQuery, "expected a field", field.value)
+	}
+	if err := validateField(field.value); err != nil {
+		return nil, err
+	}
+
+	op := p.next()
+	if op.kind != operatorToken {
+		return nil, fmt.Errorf(invalidQuery, "expected an operator", op.value)
+	}
+	value := p.next()
+	if value.kind != wordToken && value.kind != stringToken {
+		return nil, fmt.Errorf(invalidQuery, "expected a value", value.value)
+	}
+
+	if slices.Contains(numberFields, field.value) {
+		return numberPredicate(field.value, op.value, value.value)
+	}
+	return stringPredicate(field.value, op.value, value.value)
+}
+
+func numberPredicate(field, op, value string) (matcher, error) {
+	n, err := parseNumber(value)
+	if err != nil {
+		return nil, fmt.Errorf(invalidNumber, field, value)
+	}
+
+	var compare func(v int64) bool
+	switch op {
+	case "=":
+		compare = func(v int64) bool { return v == n }
+	case "!=":
+		compare = func(v int64) bool { return v != n }
+	case "<":
+		compare = func(v int64) bool { return v < n }
+	case "<=":
+		compare = func(v int64) bool { return v <= n }
+	case ">":
+		compare = func(v int64) bool { return v > n }
+	case ">=":
+		compare = func(v int64) bool { return v >= n }
+	default:
+		return nil, fmt.Errorf(invalidOperator, op, field)
+	}
+	return func(f *File) bool { return compare(f.Number(field)) }, nil
+}
+
+// parseNumber parses an integer with an optional k (thousand) or m (million)
+// suffix.
+func parseNumber(value string) (int64, error) {
+	if value == "" {
+		return 0, strconv.ErrSyntax
+	}
+	multiplier := int64(1)
+	switch strings.ToLower(value[len(value)-1:]) {
+	case "k":
+		multiplier, value = 1000, value[:len(value)-1]
+	case "m":
+		multiplier, value = 1000000, value[:len(value)-1]
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	return n * multiplier, err
+}
+
+func stringPredicate(field, op, value string) (matcher, error) {
+	if field == "ext" && value != "" && !strings.HasPrefix(value, ".") && op != "=~" {
+		value = "." + value
+	}
+	equal := func(s string) bool { return s == value }
+	if field == "language" || field == "ext" {
+		equal = func(s string) bool { return strings.EqualFold(s, value) }
+	}
+
+	switch op {
+	case "=":
+		return func(f *File) bool { return equal(f.String(field)) }, nil
+	case "!=":
+		return func(f *File) bool { return !equal(f.String(field)) }, nil
+	case "~":
+		re, err := regexp.Compile(globToRegexp(value))
+		if err != nil {
+			return nil, fmt.Errorf(invalidRegexp, value, err)
+		}
+		return func(f *File) bool { return re.MatchString(f.String(field)) }, nil
+	case "=~":
+		re, err := regexp.Compile(value)
+		if err != nil {
+			return nil, fmt.Errorf(invalidRegexp, value, err)
+		}
+		return func(f *File) bool { return re.MatchString(f.String(field)) }, nil
+	}
+	return nil, fmt.Errorf(invalidOperator, op, field)
+}
+
+// globToRegexp translates a glob into an anchored regular expression. * and
+// ? stay within a path segment while ** spans directories.
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; {
+		case strings.HasPrefix(glob[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return b.String()
+}
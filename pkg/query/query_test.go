@@ -0,0 +1,158 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	files := []*File{
+		newFile("main.go", "package main\n\nfunc main() {}\n"),
+		newFile("cmd/octomap/root.go", "package cmd\n"),
+		newFile("pkg/processor/processor_test.go", "package processor\n"),
+		newFile("Dockerfile", "FROM golang\n"),
+		newFile("docs/README.md", "# Docs\n"),
+		newTreeFile("assets/logo.svg", 48000),
+	}
+
+	tests := []struct {
+		name     string
+		expr     string
+		expected []string
+		fields   []string
+	}{
+		{
+			name:     "empty query",
+			expr:     "",
+			expected: []string{"main.go", "cmd/octomap/root.go", "pkg/processor/processor_test.go", "Dockerfile", "docs/README.md", "assets/logo.svg"},
+			fields:   []string{"path"},
+		},
+		{
+			name:     "glob spanning directories",
+			expr:     `path ~ "**/*.go"`,
+			expected: []string{"main.go", "cmd/octomap/root.go", "pkg/processor/processor_test.go"},
+			fields:   []string{"path"},
+		},
+		{
+			name:     "glob within a directory",
+			expr:     `path ~ "cmd/*/*.go"`,
+			expected: []string{"cmd/octomap/root.go"},
+			fields:   []string{"path"},
+		},
+		{
+			name:     "name equality",
+			expr:     "name = Dockerfile",
+			expected: []string{"Dockerfile"},
+			fields:   []string{"path"},
+		},
+		{
+			name:     "extension without dot",
+			expr:     "ext = MD",
+			expected: []string{"docs/README.md"},
+			fields:   []string{"path"},
+		},
+		{
+			name:     "language and negated regexp",
+			expr:     `where language = go and not name =~ "_test\.go$"`,
+			expected: []string{"main.go", "cmd/octomap/root.go"},
+			fields:   []string{"path"},
+		},
+		{
+			name:     "size with suffix",
+			expr:     "size > 10k",
+			expected: []string{"assets/logo.svg"},
+			fields:   []string{"path"},
+		},
+		{
+			name:     "grouping with or",
+			expr:     "select path, tokens where (dir = docs or lines >= 3) and tokens < 1k",
+			expected: []string{"main.go", "docs/README.md"},
+			fields:   []string{"path", "tokens"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := Parse(tt.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tt.fields, q.Fields)
+
+			var matched []string
+			for _, f := range files {
+				if q.Match(f) {
+					matched = append(matched, f.Path)
+				}
+			}
+			assert.Equal(t, tt.expected, matched)
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		expr     string
+		expected string
+	}{
+		{
+			name:     "unknown field",
+			expr:     "owner = me",
+			expected: "unknown field \"owner\"",
+		},
+		{
+			name:     "unknown select field",
+			expr:     "select path, owner",
+			expected: "unknown field \"owner\"",
+		},
+		{
+			name:     "missing value",
+			expr:     "size >",
+			expected: "expected a value",
+		},
+		{
+			name:     "invalid number",
+			expr:     "tokens > many",
+			expected: "expected a number for tokens",
+		},
+		{
+			name:     "ordering strings",
+			expr:     "path > a",
+			expected: "operator \">\" cannot be used with path",
+		},
+		{
+			name:     "glob on numbers",
+			expr:     "size ~ 10",
+			expected: "operator \"~\" cannot be used with size",
+		},
+		{
+			name:     "unclosed group",
+			expr:     "(size > 1",
+			expected: "expected )",
+		},
+		{
+			name:     "unterminated string",
+			expr:     `path ~ "cmd/**`,
+			expected: "unterminated string",
+		},
+		{
+			name:     "trailing tokens",
+			expr:     "size > 1 size",
+			expected: "unexpected token",
+		},
+		{
+			name:     "bad regexp",
+			expr:     `path =~ "("`,
+			expected: "bad regular expression",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.expr)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expected)
+		})
+	}
+}
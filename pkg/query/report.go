@@ -0,0 +1,217 @@
+package query
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"sort"
+
+	"github.com/iamhectorsosa/octomap/pkg/compress"
+	"github.com/iamhectorsosa/octomap/pkg/language"
+	"github.com/iamhectorsosa/octomap/pkg/processor"
+)
+
+const (
+	errDecodeReport   = "failed to decode report\n%v\n"
+	invalidReportItem = "invalid report, unexpected value at %q\n"
+)
+
+// File is a file of a report with its metadata. Tree-only reports only
+// provide sizes, so their files have no content.
+type File struct {
+	Path     string
+	Content  string
+	Language string
+	Size     int64
+	Tokens   int
+	Lines    int
+}
+
+// record is a file of an NDJSON report.
+type record struct {
+	Path     *string `json:"path"`
+	Content  *string `json:"content"`
+	Language string  `json:"language"`
+	Size     *int64  `json:"size"`
+}
+
+func newFile(filePath, content string) *File {
+	return &File{
+		Path:     filePath,
+		Content:  content,
+		Language: language.Detect(filePath, content),
+		Size:     int64(len(content)),
+		Tokens:   processor.EstimateTokens(content),
+		Lines:    processor.CountLines(content),
+	}
+}
+
+func newTreeFile(filePath string, size int64) *File {
+	lang := language.DetectName(filePath)
+	if lang == "" {
+		lang = language.Other
+	}
+	return &File{Path: filePath, Language: lang, Size: size, Tokens: processor.EstimateSizeTokens(size)}
+}
+
+// String returns a string field of the file.
+func (f *File) String(field string) string {
+	switch field {
+	case "path":
+		return f.Path
+	case "dir":
+		if dir := path.Dir(f.Path); dir != "." {
+			return dir
+		}
+		return ""
+	case "name":
+		return path.Base(f.Path)
+	case "ext":
+		return path.Ext(f.Path)
+	case "language":
+		return f.Language
+	case "content":
+		return f.Content
+	}
+	return ""
+}
+
+// Number returns a numeric field of the file.
+func (f *File) Number(field string) int64 {
+	switch field {
+	case "size":
+		return f.Size
+	case "tokens":
+		return int64(f.Tokens)
+	case "lines":
+		return int64(f.Lines)
+	}
+	return 0
+}
+
+// Value returns any field of the file.
+func (f *File) Value(field string) any {
+	if slices.Contains(numberFields, field) {
+		return f.Number(field)
+	}
+	return f.String(field)
+}
+
+// Read calls fn for every file of a report. Nested reports, as written by
+// octomap, and flat reports mapping paths to contents or sizes are decoded
+// as a whole. NDJSON reports, one {"path", "content"} object per line, are
+// streamed one file at a time. Gzip and zstd reports are decompressed.
+func Read(r io.Reader, fn func(f *File) error) error {
+	rc, err := compress.NewReader(r)
+	if err != nil {
+		return fmt.Errorf(errDecodeReport, err)
+	}
+	defer rc.Close()
+
+	decoder := json.NewDecoder(rc)
+
+	var first json.RawMessage
+	if err := decoder.Decode(&first); err != nil {
+		return fmt.Errorf(errDecodeReport, err)
+	}
+
+	if !decoder.More() {
+		var report map[string]any
+		objectDecoder := json.NewDecoder(bytes.NewReader(first))
+		objectDecoder.UseNumber()
+		if err := objectDecoder.Decode(&report); err != nil {
+			return fmt.Errorf(errDecodeReport, err)
+		}
+		if !isRecord(report) {
+			return walk(report, "", fn)
+		}
+	}
+
+	if err := readRecord(first, fn); err != nil {
+		return err
+	}
+	for decoder.More() {
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			return fmt.Errorf(errDecodeReport, err)
+		}
+		if err := readRecord(raw, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isRecord reports whether an object is an NDJSON file record rather than a
+// report with a file named path.
+func isRecord(v map[string]any) bool {
+	if _, ok := v["path"].(string); !ok {
+		return false
+	}
+	for key := range v {
+		switch key {
+		case "path", "content", "size", "language":
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func readRecord(raw json.RawMessage, fn func(f *File) error) error {
+	var rec record
+	if err := json.Unmarshal(raw, &rec); err != nil || rec.Path == nil {
+		return fmt.Errorf(invalidReportItem, string(raw))
+	}
+
+	var f *File
+	switch {
+	case rec.Content != nil:
+		f = newFile(*rec.Path, *rec.Content)
+	case rec.Size != nil:
+		f = newTreeFile(*rec.Path, *rec.Size)
+	default:
+		return fmt.Errorf(invalidReportItem, *rec.Path)
+	}
+	if rec.Language != "" {
+		f.Language = rec.Language
+	}
+	return fn(f)
+}
+
+// walk visits the files of a nested or flat report in path order.
+func walk(node map[string]any, prefix string, fn func(f *File) error) error {
+	names := make([]string, 0, len(node))
+	for name := range node {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		filePath := prefix + name
+		switch v := node[name].(type) {
+		case map[string]any:
+			if err := walk(v, filePath+"/", fn); err != nil {
+				return err
+			}
+		case string:
+			if err := fn(newFile(filePath, v)); err != nil {
+				return err
+			}
+		case json.Number:
+			size, err := v.Int64()
+			if err != nil {
+				return fmt.Errorf(invalidReportItem, filePath)
+			}
+			if err := fn(newTreeFile(filePath, size)); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf(invalidReportItem, filePath)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package query
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/iamhectorsosa/octomap/pkg/compress"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRead(t *testing.T) {
+	tests := []struct {
+		name     string
+		report   string
+		expected []File
+	}{
+		{
+			name:   "nested report",
+			report: `{"main.go": "package main\n", "docs": {"README.md": "# Docs\n"}}`,
+			expected: []File{
+				{Path: "docs/README.md", Content: "# Docs\n", Language: "Markdown", Size: 7, Tokens: 2, Lines: 1},
+				{Path: "main.go", Content: "package main\n", Language: "Go", Size: 13, Tokens: 4, Lines: 1},
+			},
+		},
+		{
+			name:   "flat report",
+			report: `{"main.go": "package main\n", "docs/README.md": "# Docs\n"}`,
+			expected: []File{
+				{Path: "docs/README.md", Content: "# Docs\n", Language: "Markdown", Size: 7, Tokens: 2, Lines: 1},
+				{Path: "main.go", Content: "package main\n", Language: "Go", Size: 13, Tokens: 4, Lines: 1},
+			},
+		},
+		{
+			name:   "tree-only report",
+			report: `{"cmd": {"main.go": 120}}`,
+			expected: []File{
+				{Path: "cmd/main.go", Language: "Go", Size: 120, Tokens: 30},
+			},
+		},
+		{
+			name: "ndjson report",
+			report: `{"path": "main.go", "content": "package main\n"}
+{"path": "docs/README.md", "content": "# Docs\n", "language": "Docs"}
+{"path": "assets/logo.svg", "size": 4000}
+`,
+			expected: []File{
+				{Path: "main.go", Content: "package main\n", Language: "Go", Size: 13, Tokens: 4, Lines: 1},
+				{Path: "docs/README.md", Content: "# Docs\n", Language: "Docs", Size: 7, Tokens: 2, Lines: 1},
+				{Path: "assets/logo.svg", Language: "Other", Size: 4000, Tokens: 1000},
+			},
+		},
+		{
+			name:   "single ndjson record",
+			report: `{"path": "main.go", "content": "package main\n"}`,
+			expected: []File{
+				{Path: "main.go", Content: "package main\n", Language: "Go", Size: 13, Tokens: 4, Lines: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var files []File
+			err := Read(strings.NewReader(tt.report), func(f *File) error {
+				files = append(files, *f)
+				return nil
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, files)
+		})
+	}
+}
+
+func TestReadCompressed(t *testing.T) {
+	for _, method := range []string{compress.Gzip, compress.Zstd} {
+		t.Run(method, func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := compress.NewWriter(&buf, method)
+			require.NoError(t, err)
+			_, err = io.WriteString(w, `{"cmd": {"main.go": "package main\n"}}`)
+			require.NoError(t, err)
+			require.NoError(t, w.Close())
+
+			var paths []string
+			require.NoError(t, Read(&buf, func(f *File) error {
+				paths = append(paths, f.Path)
+				return nil
+			}))
+			assert.Equal(t, []string{"cmd/main.go"}, paths)
+		})
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		report string
+	}{
+		{name: "not json", report: "octomap"},
+		{name: "unexpected value", report: `{"main.go": true}`},
+		{name: "record without content", report: "{\"path\": \"a.go\"}\n{\"path\": \"b.go\"}\n"},
+		{name: "truncated ndjson", report: "{\"path\": \"a.go\", \"content\": \"\"}\n{\"path\": "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Read(strings.NewReader(tt.report), func(f *File) error { return nil })
+			assert.Error(t, err)
+		})
+	}
+}